@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// checkpointDirEnvVar, if set, switches the checkpoint store from in-memory
+// to a directory of files (e.g. backed by a PersistentVolume), so tailers
+// resume from their last observed timestamp across exporter restarts.
+const checkpointDirEnvVar = "LOG_CHECKPOINT_DIR"
+
+// CheckpointStore records, per tailer, the timestamp of the last log line
+// successfully processed so a reconnecting tailer can resume with
+// SinceTime instead of re-reading (and double-counting) old lines.
+type CheckpointStore interface {
+	Get(key string) (time.Time, bool)
+	Set(key string, ts time.Time) error
+
+	// Forget deletes key's checkpoint, if any. Callers must invoke this
+	// when a (pod, container, restartCount) tailer is torn down for good
+	// (see stopScrapersForPod in discovery.go) - keys are never otherwise
+	// removed, so without this byKey/the checkpoint directory grows by one
+	// entry per dead pod forever.
+	Forget(key string) error
+}
+
+// newCheckpointStore builds a CheckpointStore from environment
+// configuration: a file-backed store if checkpointDirEnvVar is set,
+// otherwise an in-memory store (checkpoints are lost on restart, matching
+// the exporter's original behavior).
+func newCheckpointStore() (CheckpointStore, error) {
+	if dir := os.Getenv(checkpointDirEnvVar); dir != "" {
+		return newFileCheckpointStore(dir)
+	}
+	return newInMemoryCheckpointStore(), nil
+}
+
+// checkpointKey identifies a single (pod, container, restartCount) tailer.
+// Keying on pod UID rather than name means a deleted-and-recreated pod
+// (e.g. a StatefulSet pod reusing its name) gets a fresh checkpoint instead
+// of resuming from its predecessor's. Including the restart count means a
+// tailer started after a container restart gets a fresh checkpoint; a tailer
+// that is already running keeps the key it started with across later
+// restarts of its container (see activeScraper in discovery.go).
+func checkpointKey(podUID, containerName string, restartCount int32) string {
+	return fmt.Sprintf("%s/%s/%d", podUID, containerName, restartCount)
+}
+
+type inMemoryCheckpointStore struct {
+	mu     sync.RWMutex
+	byKey  map[string]time.Time
+}
+
+func newInMemoryCheckpointStore() *inMemoryCheckpointStore {
+	return &inMemoryCheckpointStore{byKey: make(map[string]time.Time)}
+}
+
+func (s *inMemoryCheckpointStore) Get(key string) (time.Time, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ts, ok := s.byKey[key]
+	return ts, ok
+}
+
+func (s *inMemoryCheckpointStore) Set(key string, ts time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byKey[key] = ts
+	return nil
+}
+
+func (s *inMemoryCheckpointStore) Forget(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.byKey, key)
+	return nil
+}
+
+// fileCheckpointStore persists each checkpoint as a small file under dir,
+// named after a sanitized version of its key, containing an RFC3339Nano
+// timestamp.
+type fileCheckpointStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func newFileCheckpointStore(dir string) (*fileCheckpointStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint dir %s: %v", dir, err)
+	}
+	return &fileCheckpointStore{dir: dir}, nil
+}
+
+func (s *fileCheckpointStore) path(key string) string {
+	return filepath.Join(s.dir, strings.ReplaceAll(key, "/", "_"))
+}
+
+func (s *fileCheckpointStore) Get(key string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return time.Time{}, false
+	}
+	ts, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return ts, true
+}
+
+func (s *fileCheckpointStore) Set(key string, ts time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return os.WriteFile(s.path(key), []byte(ts.Format(time.RFC3339Nano)), 0o644)
+}
+
+func (s *fileCheckpointStore) Forget(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove checkpoint file for %s: %v", key, err)
+	}
+	return nil
+}
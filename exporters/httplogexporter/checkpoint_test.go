@@ -0,0 +1,84 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryCheckpointStoreGetSet(t *testing.T) {
+	store := newInMemoryCheckpointStore()
+
+	_, ok := store.Get("ns/pod/container/0")
+	assert.False(t, ok)
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.NoError(t, store.Set("ns/pod/container/0", ts))
+
+	got, ok := store.Get("ns/pod/container/0")
+	assert.True(t, ok)
+	assert.True(t, ts.Equal(got))
+}
+
+func TestFileCheckpointStoreGetSet(t *testing.T) {
+	dir := t.TempDir()
+	store, err := newFileCheckpointStore(dir)
+	assert.NoError(t, err)
+
+	_, ok := store.Get("ns/pod/container/0")
+	assert.False(t, ok)
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.NoError(t, store.Set("ns/pod/container/0", ts))
+
+	got, ok := store.Get("ns/pod/container/0")
+	assert.True(t, ok)
+	assert.True(t, ts.Equal(got))
+
+	// The key is sanitized into a flat filename, not nested directories.
+	matches, err := filepath.Glob(filepath.Join(dir, "ns_pod_container_0"))
+	assert.NoError(t, err)
+	assert.Len(t, matches, 1)
+}
+
+func TestCheckpointKey(t *testing.T) {
+	assert.Equal(t, "pod-uid-123/app/2", checkpointKey("pod-uid-123", "app", 2))
+}
+
+func TestInMemoryCheckpointStoreForget(t *testing.T) {
+	store := newInMemoryCheckpointStore()
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.NoError(t, store.Set("ns/pod/container/0", ts))
+
+	assert.NoError(t, store.Forget("ns/pod/container/0"))
+
+	_, ok := store.Get("ns/pod/container/0")
+	assert.False(t, ok)
+
+	// Forgetting a key with no checkpoint is a no-op, not an error.
+	assert.NoError(t, store.Forget("never/set/container/0"))
+}
+
+func TestFileCheckpointStoreForget(t *testing.T) {
+	dir := t.TempDir()
+	store, err := newFileCheckpointStore(dir)
+	assert.NoError(t, err)
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.NoError(t, store.Set("ns/pod/container/0", ts))
+
+	assert.NoError(t, store.Forget("ns/pod/container/0"))
+
+	_, ok := store.Get("ns/pod/container/0")
+	assert.False(t, ok)
+
+	matches, err := filepath.Glob(filepath.Join(dir, "ns_pod_container_0"))
+	assert.NoError(t, err)
+	assert.Empty(t, matches, "checkpoint file should be removed from disk")
+
+	// Forgetting a key with no checkpoint file is a no-op, not an error.
+	assert.NoError(t, store.Forget("never/set/container/0"))
+}
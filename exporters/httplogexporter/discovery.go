@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Annotations that opt a pod into scraping, modeled on Prometheus'
+// discovery/kubernetes annotation-based pod discovery.
+const (
+	// scrapeAnnotation marks a pod as a scrape target when set to "true".
+	scrapeAnnotation = "o11y.io/scrape"
+
+	// scrapeContainerAnnotation restricts scraping to a single named
+	// container; when unset, every container in the pod is scraped.
+	scrapeContainerAnnotation = "o11y.io/container"
+)
+
+// PodDiscovery watches Pods in a namespace via the Kubernetes watch API and
+// invokes OnPodAdded/OnPodUpdated/OnPodDeleted as eligible pods come and go,
+// replacing the old approach of re-Listing the namespace every scrape
+// interval.
+type PodDiscovery struct {
+	clientset   kubernetes.Interface
+	namespace   string
+	podSelector string
+
+	OnPodAdded   func(pod *corev1.Pod)
+	OnPodUpdated func(oldPod, newPod *corev1.Pod)
+	OnPodDeleted func(pod *corev1.Pod)
+
+	// OnWatchReconnect, if set, is called each time the underlying watch is
+	// re-established (e.g. after expiring or erroring), but not for the
+	// initial List+Watch when Run starts.
+	OnWatchReconnect func()
+}
+
+// NewPodDiscovery creates a PodDiscovery scoped to namespace, optionally
+// restricted to pods matching podSelector.
+func NewPodDiscovery(clientset kubernetes.Interface, namespace, podSelector string) *PodDiscovery {
+	return &PodDiscovery{clientset: clientset, namespace: namespace, podSelector: podSelector}
+}
+
+// Run starts the pod informer and blocks processing events until ctx is
+// canceled.
+func (d *PodDiscovery) Run(ctx context.Context) error {
+	// The informer's Reflector calls ListFunc again, with automatic
+	// exponential backoff, whenever WatchFunc's stream ends or errors - so
+	// every List beyond the first one is a reconnect.
+	haveListedOnce := false
+
+	listWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.LabelSelector = d.podSelector
+			if haveListedOnce && d.OnWatchReconnect != nil {
+				d.OnWatchReconnect()
+			}
+			haveListedOnce = true
+			return d.clientset.CoreV1().Pods(d.namespace).List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.LabelSelector = d.podSelector
+			return d.clientset.CoreV1().Pods(d.namespace).Watch(ctx, options)
+		},
+	}
+
+	_, informer := cache.NewInformer(listWatch, &corev1.Pod{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			pod, ok := obj.(*corev1.Pod)
+			if !ok || !isScrapeEligible(pod) || d.OnPodAdded == nil {
+				return
+			}
+			d.OnPodAdded(pod)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldPod, ok := oldObj.(*corev1.Pod)
+			if !ok {
+				return
+			}
+			newPod, ok := newObj.(*corev1.Pod)
+			if !ok {
+				return
+			}
+			if d.OnPodUpdated != nil {
+				d.OnPodUpdated(oldPod, newPod)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			pod, ok := obj.(*corev1.Pod)
+			if !ok {
+				tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					return
+				}
+				pod, ok = tombstone.Obj.(*corev1.Pod)
+				if !ok {
+					return
+				}
+			}
+			if d.OnPodDeleted != nil {
+				d.OnPodDeleted(pod)
+			}
+		},
+	})
+
+	informer.Run(ctx.Done())
+	return nil
+}
+
+// isScrapeEligible reports whether pod opted in via scrapeAnnotation and is
+// currently running.
+func isScrapeEligible(pod *corev1.Pod) bool {
+	return pod.Annotations[scrapeAnnotation] == "true" && pod.Status.Phase == corev1.PodRunning
+}
+
+// podLogFormat returns the format name pinned by logFormatAnnotation, or ""
+// to let the tailer autodetect it (see builtinParserByFormat).
+func podLogFormat(pod *corev1.Pod) string {
+	return pod.Annotations[logFormatAnnotation]
+}
+
+// eligibleContainers returns the container names in pod that should be
+// scraped: every container, unless scrapeContainerAnnotation names one.
+func eligibleContainers(pod *corev1.Pod) []string {
+	if name := pod.Annotations[scrapeContainerAnnotation]; name != "" {
+		return []string{name}
+	}
+	names := make([]string, 0, len(pod.Spec.Containers))
+	for _, c := range pod.Spec.Containers {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// scraperKey identifies a single (pod, container) tailer, keyed by pod UID
+// so a deleted-and-recreated pod with the same name gets its own entry.
+func scraperKey(pod *corev1.Pod, containerName string) string {
+	return string(pod.UID) + "/" + containerName
+}
+
+// activeScraper is the value stored in HTTPLogExporter.activeScrapers for
+// each running tailer goroutine.
+type activeScraper struct {
+	cancel context.CancelFunc
+
+	// restartCount is the container's restart count at the moment this
+	// tailer was started, i.e. the one baked into its checkpoint key. A
+	// tailer outlives later restarts of its container (startScrapersForPod
+	// skips re-creating it while activeScrapers already holds its key), so
+	// by the time stopScrapersForPod runs the pod's current restart count
+	// may have moved on - recomputing it there would forget the wrong
+	// checkpoint key and leak the real one.
+	restartCount int32
+}
+
+// runDiscovery fans out pod discovery across e's configured namespace scope:
+// a dynamically-matched set of namespaces when namespaceSelector is set
+// (cluster-scoped mode), otherwise the fixed, possibly comma-separated,
+// namespace list in e.namespace.
+func (e *HTTPLogExporter) runDiscovery(ctx context.Context) error {
+	if e.namespaceSelector != "" {
+		return e.runClusterScopedDiscovery(ctx)
+	}
+
+	namespaces := parseTargetNamespaces(e.namespace)
+	errCh := make(chan error, len(namespaces))
+	for _, namespace := range namespaces {
+		namespace := namespace
+		go func() {
+			errCh <- e.runNamespaceDiscovery(ctx, namespace)
+		}()
+	}
+	// A single namespace's discovery loop only returns (non-nil) on a fatal
+	// error; the caller (main) treats that as fatal for the whole exporter.
+	return <-errCh
+}
+
+// runClusterScopedDiscovery watches Namespaces matching e.namespaceSelector
+// and starts/stops a per-namespace runNamespaceDiscovery goroutine as
+// matching namespaces come and go.
+func (e *HTTPLogExporter) runClusterScopedDiscovery(ctx context.Context) error {
+	var cancelFuncs sync.Map // namespace -> context.CancelFunc
+
+	nsDiscovery := NewNamespaceDiscovery(e.clientset, e.namespaceSelector)
+	nsDiscovery.OnNamespaceAdded = func(namespace string) {
+		if _, exists := cancelFuncs.Load(namespace); exists {
+			return
+		}
+		nsCtx, cancel := context.WithCancel(ctx)
+		cancelFuncs.Store(namespace, cancel)
+		go func() {
+			if err := e.runNamespaceDiscovery(nsCtx, namespace); err != nil && nsCtx.Err() == nil {
+				e.log().Error("pod discovery for namespace stopped", "namespace", namespace, "error", err)
+			}
+		}()
+	}
+	nsDiscovery.OnNamespaceRemoved = func(namespace string) {
+		if cancel, exists := cancelFuncs.LoadAndDelete(namespace); exists {
+			cancel.(context.CancelFunc)()
+		}
+	}
+
+	return nsDiscovery.Run(ctx)
+}
+
+// runNamespaceDiscovery drives e's scrapers off pod add/update/delete events
+// within a single namespace, replacing the old namespace-wide List-based
+// scrapeLogs loop.
+func (e *HTTPLogExporter) runNamespaceDiscovery(ctx context.Context, namespace string) error {
+	discovery := NewPodDiscovery(e.clientset, namespace, e.podSelector)
+
+	discovery.OnPodAdded = func(pod *corev1.Pod) {
+		e.startScrapersForPod(ctx, pod)
+	}
+	discovery.OnPodUpdated = func(oldPod, newPod *corev1.Pod) {
+		switch {
+		case isScrapeEligible(newPod) && !isScrapeEligible(oldPod):
+			e.startScrapersForPod(ctx, newPod)
+		case !isScrapeEligible(newPod) && isScrapeEligible(oldPod):
+			e.stopScrapersForPod(oldPod)
+		}
+	}
+	discovery.OnPodDeleted = func(pod *corev1.Pod) {
+		e.stopScrapersForPod(pod)
+	}
+	discovery.OnWatchReconnect = func() {
+		e.watchReconnectsTotal.WithLabelValues(namespace).Inc()
+	}
+
+	return discovery.Run(ctx)
+}
+
+// podHasActiveScrapers reports whether any container of pod currently has an
+// active tailer goroutine registered in e.activeScrapers.
+func (e *HTTPLogExporter) podHasActiveScrapers(pod *corev1.Pod) bool {
+	prefix := string(pod.UID) + "/"
+	found := false
+	e.activeScrapers.Range(func(key, _ interface{}) bool {
+		if strings.HasPrefix(key.(string), prefix) {
+			found = true
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// startScrapersForPod launches one ContainerTailer goroutine per eligible
+// container in pod, streaming its logs until stopScrapersForPod cancels it.
+func (e *HTTPLogExporter) startScrapersForPod(ctx context.Context, pod *corev1.Pod) {
+	e.targetInfo.WithLabelValues(pod.Namespace, pod.Name, pod.Spec.NodeName).Set(1)
+
+	if !e.podHasActiveScrapers(pod) {
+		e.podsWatched.WithLabelValues(pod.Namespace).Inc()
+	}
+
+	for _, containerName := range eligibleContainers(pod) {
+		key := scraperKey(pod, containerName)
+		if _, exists := e.activeScrapers.Load(key); exists {
+			continue
+		}
+
+		restartCount := containerRestartCount(pod, containerName)
+		scraperCtx, cancel := context.WithCancel(ctx)
+		e.activeScrapers.Store(key, &activeScraper{cancel: cancel, restartCount: restartCount})
+
+		tailer := &ContainerTailer{
+			exporter:      e,
+			namespace:     pod.Namespace,
+			podUID:        string(pod.UID),
+			podName:       pod.Name,
+			containerName: containerName,
+			restartCount:  restartCount,
+			logFormat:     podLogFormat(pod),
+		}
+		go tailer.Run(scraperCtx)
+	}
+}
+
+// stopScrapersForPod cancels every active tailer goroutine for pod.
+func (e *HTTPLogExporter) stopScrapersForPod(pod *corev1.Pod) {
+	e.targetInfo.WithLabelValues(pod.Namespace, pod.Name, pod.Spec.NodeName).Set(0)
+
+	hadActiveScrapers := e.podHasActiveScrapers(pod)
+
+	for _, containerName := range eligibleContainers(pod) {
+		key := scraperKey(pod, containerName)
+		var restartCount int32
+		if v, exists := e.activeScrapers.LoadAndDelete(key); exists {
+			scraper := v.(*activeScraper)
+			scraper.cancel()
+			restartCount = scraper.restartCount
+		} else {
+			restartCount = containerRestartCount(pod, containerName)
+		}
+		if e.pathNormalizer != nil {
+			e.pathNormalizer.Forget(pathNormalizerContainerKey(pod.Namespace, pod.Name, containerName))
+		}
+		if e.checkpoints != nil {
+			if err := e.checkpoints.Forget(checkpointKey(string(pod.UID), containerName, restartCount)); err != nil {
+				e.log().Warn("failed to forget checkpoint",
+					"namespace", pod.Namespace,
+					"pod", pod.Name,
+					"container", containerName,
+					"error", err,
+				)
+			}
+		}
+	}
+
+	if hadActiveScrapers {
+		e.podsWatched.WithLabelValues(pod.Namespace).Dec()
+	}
+}
+
+// containerRestartCount looks up the current restart count for
+// containerName from pod's status, defaulting to 0 if not yet reported.
+func containerRestartCount(pod *corev1.Pod, containerName string) int32 {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == containerName {
+			return status.RestartCount
+		}
+	}
+	return 0
+}
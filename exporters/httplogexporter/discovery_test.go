@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestIsScrapeEligible(t *testing.T) {
+	tests := []struct {
+		name     string
+		pod      *corev1.Pod
+		expected bool
+	}{
+		{
+			name: "opted in and running",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{scrapeAnnotation: "true"}},
+				Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+			},
+			expected: true,
+		},
+		{
+			name: "opted in but pending",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{scrapeAnnotation: "true"}},
+				Status:     corev1.PodStatus{Phase: corev1.PodPending},
+			},
+			expected: false,
+		},
+		{
+			name: "running but not opted in",
+			pod: &corev1.Pod{
+				Status: corev1.PodStatus{Phase: corev1.PodRunning},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isScrapeEligible(tt.pod))
+		})
+	}
+}
+
+func TestEligibleContainers(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}, {Name: "sidecar"}}},
+	}
+
+	assert.ElementsMatch(t, []string{"app", "sidecar"}, eligibleContainers(pod))
+
+	pod.Annotations = map[string]string{scrapeContainerAnnotation: "app"}
+	assert.Equal(t, []string{"app"}, eligibleContainers(pod))
+}
+
+func TestPodHasActiveScrapers(t *testing.T) {
+	exporter := &HTTPLogExporter{}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{UID: types.UID("pod-uid-1")},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+	}
+
+	assert.False(t, exporter.podHasActiveScrapers(pod))
+
+	_, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	exporter.activeScrapers.Store(scraperKey(pod, "app"), &activeScraper{cancel: cancel})
+
+	assert.True(t, exporter.podHasActiveScrapers(pod))
+
+	otherPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{UID: types.UID("pod-uid-2")}}
+	assert.False(t, exporter.podHasActiveScrapers(otherPod))
+}
+
+func TestPodLogFormat(t *testing.T) {
+	pod := &corev1.Pod{}
+	assert.Equal(t, "", podLogFormat(pod))
+
+	pod.Annotations = map[string]string{logFormatAnnotation: "envoy-json"}
+	assert.Equal(t, "envoy-json", podLogFormat(pod))
+}
+
+// TestStopScrapersForPodForgetsPathNormalizer verifies that stopScrapersForPod
+// releases the deleted pod's route bookkeeping in PathNormalizer, not just
+// its tailer goroutine - otherwise pod churn (deployments, autoscaling,
+// restarts) leaks one entry in PathNormalizer.seen per pod name forever.
+func TestStopScrapersForPodForgetsPathNormalizer(t *testing.T) {
+	exporter := &HTTPLogExporter{
+		pathNormalizer: NewPathNormalizer(nil, nil, 1),
+		targetInfo: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "target_info", Help: "Test metric"},
+			[]string{"namespace", "pod", "node"},
+		),
+		podsWatched: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "pods_watched", Help: "Test metric"},
+			[]string{"namespace"},
+		),
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{UID: types.UID("pod-uid-1"), Name: "pod", Namespace: "ns"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+	}
+	containerKey := pathNormalizerContainerKey("ns", "pod", "app")
+
+	// Occupy the container's entire route budget with a single route.
+	route, capped := exporter.pathNormalizer.Normalize(containerKey, "/a")
+	assert.Equal(t, "/a", route)
+	assert.False(t, capped)
+
+	exporter.stopScrapersForPod(pod)
+
+	// Forgotten: the same containerKey has its budget back, so a different
+	// route is admitted rather than immediately colliding with stale state.
+	route, capped = exporter.pathNormalizer.Normalize(containerKey, "/b")
+	assert.Equal(t, "/b", route)
+	assert.False(t, capped)
+}
+
+// TestStopScrapersForPodForgetsCheckpoint verifies that stopScrapersForPod
+// also releases the deleted pod's checkpoint, matching the PathNormalizer
+// cleanup above - otherwise inMemoryCheckpointStore.byKey (or, with
+// LOG_CHECKPOINT_DIR set, a file on disk) leaks one entry per dead pod
+// forever.
+func TestStopScrapersForPodForgetsCheckpoint(t *testing.T) {
+	exporter := &HTTPLogExporter{
+		checkpoints: newInMemoryCheckpointStore(),
+		targetInfo: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "target_info", Help: "Test metric"},
+			[]string{"namespace", "pod", "node"},
+		),
+		podsWatched: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "pods_watched", Help: "Test metric"},
+			[]string{"namespace"},
+		),
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{UID: types.UID("pod-uid-1"), Name: "pod", Namespace: "ns"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "app", RestartCount: 2}},
+		},
+	}
+	key := checkpointKey("pod-uid-1", "app", 2)
+
+	assert.NoError(t, exporter.checkpoints.Set(key, time.Now()))
+	_, ok := exporter.checkpoints.Get(key)
+	assert.True(t, ok)
+
+	exporter.stopScrapersForPod(pod)
+
+	_, ok = exporter.checkpoints.Get(key)
+	assert.False(t, ok, "checkpoint should be forgotten once the pod is deleted")
+}
+
+// TestStopScrapersForPodForgetsCheckpointAfterInPlaceRestart verifies that
+// stopScrapersForPod forgets the checkpoint key the running tailer actually
+// used, not one recomputed from the pod's current status - a container that
+// restarts in place doesn't get a new tailer (startScrapersForPod's
+// activeScrapers exists-check skips it), so the tailer's checkpoint stays
+// keyed by the restart count it started with even as the pod's reported
+// RestartCount moves on.
+func TestStopScrapersForPodForgetsCheckpointAfterInPlaceRestart(t *testing.T) {
+	exporter := &HTTPLogExporter{
+		checkpoints: newInMemoryCheckpointStore(),
+		targetInfo: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "target_info", Help: "Test metric"},
+			[]string{"namespace", "pod", "node"},
+		),
+		podsWatched: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "pods_watched", Help: "Test metric"},
+			[]string{"namespace"},
+		),
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{UID: types.UID("pod-uid-1"), Name: "pod", Namespace: "ns"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+		Status: corev1.PodStatus{
+			// The pod's current status already reflects an in-place restart
+			// (RestartCount: 1), but the tailer registered below started
+			// when RestartCount was still 0 and was never replaced (that's
+			// what the activeScrapers exists-check in startScrapersForPod
+			// guarantees in production).
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "app", RestartCount: 1}},
+		},
+	}
+
+	_, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	exporter.activeScrapers.Store(scraperKey(pod, "app"), &activeScraper{cancel: cancel, restartCount: 0})
+
+	key := checkpointKey("pod-uid-1", "app", 0)
+	assert.NoError(t, exporter.checkpoints.Set(key, time.Now()))
+
+	exporter.stopScrapersForPod(pod)
+
+	_, ok := exporter.checkpoints.Get(key)
+	assert.False(t, ok, "checkpoint for the tailer's actual restart count should be forgotten")
+
+	staleKey := checkpointKey("pod-uid-1", "app", 1)
+	_, ok = exporter.checkpoints.Get(staleKey)
+	assert.False(t, ok, "no checkpoint should have been created under the pod's current (wrong) restart count")
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// logFormatAnnotation pins a pod's log format to one of the names recognized
+// by builtinParserByFormat, e.g. "envoy-json", tried before autodetection for
+// containers whose logs are otherwise ambiguous (see discovery.go).
+const logFormatAnnotation = "o11y.io/log-format"
+
+// builtinParserByFormat looks up a named parser for logFormatAnnotation
+// values. These are tried before e's autodetecting registry, not instead of
+// it: a format that doesn't match a given line still falls through.
+func builtinParserByFormat(format string) (LogParser, bool) {
+	switch format {
+	case "nginx-combined":
+		return &regexLogParser{
+			name:    "nginx-combined",
+			pattern: combinedLogPattern,
+			fields:  map[string]int{"timestamp": 2, "method": 3, "path": 4, "status": 5, "size": 6, "duration": 7},
+		}, true
+	case "apache-common":
+		return &regexLogParser{
+			name:    "apache-common",
+			pattern: commonLogPattern,
+			fields:  map[string]int{"timestamp": 2, "method": 3, "path": 4, "status": 5, "size": 6},
+		}, true
+	case "envoy-json":
+		return &jsonFieldLogParser{
+			name: "envoy-json",
+			fields: map[string]string{
+				"status":      "response_code",
+				"method":      "method",
+				"path":        "path",
+				"size":        "bytes_sent",
+				"duration_ms": "duration",
+				"user_agent":  "user_agent",
+			},
+		}, true
+	case "ingress-nginx-json":
+		return &jsonFieldLogParser{
+			name: "ingress-nginx-json",
+			fields: map[string]string{
+				"status":     "status",
+				"method":     "request_method",
+				"path":       "request_uri",
+				"size":       "bytes_sent",
+				"duration":   "request_time",
+				"user_agent": "http_user_agent",
+			},
+		}, true
+	case "otel-json":
+		return &otelLogRecordLineParser{}, true
+	default:
+		return nil, false
+	}
+}
+
+// otelLogRecordLineParser decodes a single line as an OpenTelemetry JSON log
+// record (the shape of each LogRecord inside the OTLP/JSON export requests
+// the receiver in otlp_receiver.go accepts), extracting the same well-known
+// HTTP attributes logRecordToEntry does.
+type otelLogRecordLineParser struct{}
+
+func (otelLogRecordLineParser) Name() string { return "otel-json" }
+
+// otelJSONLogRecord mirrors the fields of an OTLP JSON LogRecord that this
+// parser understands; everything else in the record is ignored.
+type otelJSONLogRecord struct {
+	Attributes []struct {
+		Key   string `json:"key"`
+		Value struct {
+			StringValue *string  `json:"stringValue"`
+			IntValue    *string  `json:"intValue"`
+			DoubleValue *float64 `json:"doubleValue"`
+			BoolValue   *bool    `json:"boolValue"`
+		} `json:"value"`
+	} `json:"attributes"`
+}
+
+func (p otelLogRecordLineParser) Parse(line string) (*LogEntry, bool) {
+	var record otelJSONLogRecord
+	if err := json.Unmarshal([]byte(line), &record); err != nil {
+		return nil, false
+	}
+
+	attrs := make(map[string]string, len(record.Attributes))
+	for _, kv := range record.Attributes {
+		switch {
+		case kv.Value.StringValue != nil:
+			attrs[kv.Key] = *kv.Value.StringValue
+		case kv.Value.IntValue != nil:
+			attrs[kv.Key] = *kv.Value.IntValue
+		case kv.Value.DoubleValue != nil:
+			attrs[kv.Key] = strconv.FormatFloat(*kv.Value.DoubleValue, 'f', -1, 64)
+		case kv.Value.BoolValue != nil:
+			attrs[kv.Key] = strconv.FormatBool(*kv.Value.BoolValue)
+		}
+	}
+
+	statusCode, err := strconv.Atoi(attrs[attrHTTPStatusCode])
+	if err != nil {
+		return nil, false
+	}
+
+	entry := &LogEntry{
+		Method:     attrs[attrHTTPMethod],
+		Path:       attrs[attrHTTPRoute],
+		StatusCode: statusCode,
+	}
+	if duration, err := strconv.ParseFloat(attrs[attrHTTPDuration], 64); err == nil {
+		entry.DurationSeconds = duration
+	}
+	if size, err := strconv.Atoi(attrs[attrHTTPResponseSize]); err == nil {
+		entry.ResponseSize = size
+	}
+	return entry, true
+}
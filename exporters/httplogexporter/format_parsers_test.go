@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuiltinParserByFormatUnknown(t *testing.T) {
+	_, ok := builtinParserByFormat("made-up-format")
+	assert.False(t, ok)
+}
+
+func TestBuiltinParserByFormatNginxCombined(t *testing.T) {
+	p, ok := builtinParserByFormat("nginx-combined")
+	assert.True(t, ok)
+
+	entry, matched := p.Parse(`127.0.0.1 - - [25/Dec/2019:01:17:21 +0000] "GET /api/health HTTP/1.1" 200 612`)
+	assert.True(t, matched)
+	assert.Equal(t, "GET", entry.Method)
+	assert.Equal(t, "/api/health", entry.Path)
+	assert.Equal(t, 200, entry.StatusCode)
+	assert.Equal(t, 612, entry.ResponseSize)
+}
+
+func TestBuiltinParserByFormatEnvoyJSON(t *testing.T) {
+	p, ok := builtinParserByFormat("envoy-json")
+	assert.True(t, ok)
+
+	line := `{"method":"POST","path":"/v1/orders","response_code":201,"bytes_sent":128,"duration":42,"user_agent":"curl/8.0"}`
+	entry, matched := p.Parse(line)
+	assert.True(t, matched)
+	assert.Equal(t, "POST", entry.Method)
+	assert.Equal(t, "/v1/orders", entry.Path)
+	assert.Equal(t, 201, entry.StatusCode)
+	assert.Equal(t, 128, entry.ResponseSize)
+	assert.Equal(t, "curl/8.0", entry.UserAgent)
+	assert.InDelta(t, 0.042, entry.DurationSeconds, 1e-9)
+}
+
+func TestBuiltinParserByFormatIngressNginxJSON(t *testing.T) {
+	p, ok := builtinParserByFormat("ingress-nginx-json")
+	assert.True(t, ok)
+
+	line := `{"status":"404","request_method":"GET","request_uri":"/missing","bytes_sent":"0","request_time":"0.001","http_user_agent":"curl/8.0"}`
+	entry, matched := p.Parse(line)
+	assert.True(t, matched)
+	assert.Equal(t, 404, entry.StatusCode)
+	assert.Equal(t, "GET", entry.Method)
+	assert.Equal(t, "/missing", entry.Path)
+}
+
+func TestOTelLogRecordLineParser(t *testing.T) {
+	p, ok := builtinParserByFormat("otel-json")
+	assert.True(t, ok)
+
+	line := `{"attributes":[{"key":"http.status_code","value":{"intValue":"500"}},{"key":"http.method","value":{"stringValue":"POST"}},{"key":"http.route","value":{"stringValue":"/api/orders/{id}"}},{"key":"http.duration_seconds","value":{"doubleValue":0.25}},{"key":"http.response_size_bytes","value":{"intValue":"1024"}}]}`
+	entry, matched := p.Parse(line)
+	assert.True(t, matched)
+	assert.Equal(t, 500, entry.StatusCode)
+	assert.Equal(t, "POST", entry.Method)
+	assert.Equal(t, "/api/orders/{id}", entry.Path)
+	assert.InDelta(t, 0.25, entry.DurationSeconds, 1e-9)
+	assert.Equal(t, 1024, entry.ResponseSize)
+
+	_, matched = p.Parse(`{"attributes":[]}`)
+	assert.False(t, matched)
+}
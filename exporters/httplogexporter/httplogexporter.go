@@ -1,21 +1,19 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"fmt"
-	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 )
@@ -23,39 +21,64 @@ import (
 const (
 	// Default configuration values
 	defaultNamespace     = "default"
+	// defaultScrapeInterval is the ceiling on how long the streaming
+	// tailer backs off between reconnect attempts after a container's log
+	// stream ends or errors.
 	defaultScrapeInterval = 30 * time.Second
-	defaultLogLines      = 100
-	
+
 	// Environment variable names for configuration
 	namespaceEnvVar      = "TARGET_NAMESPACE"
 	scrapeIntervalEnvVar = "SCRAPE_INTERVAL_SECONDS"
-	logLinesEnvVar      = "LOG_LINES_LIMIT"
 	podSelectorEnvVar   = "POD_SELECTOR"
+	durationBucketsEnvVar = "HTTP_DURATION_BUCKETS_SECONDS"
+	responseSizeBucketsEnvVar = "HTTP_RESPONSE_SIZE_BUCKETS_BYTES"
+)
+
+// defaultDurationBuckets and defaultResponseSizeBuckets are used when the
+// corresponding *_BUCKETS* env var is unset or unparsable.
+var (
+	defaultDurationBuckets     = prometheus.DefBuckets
+	defaultResponseSizeBuckets = prometheus.ExponentialBuckets(64, 4, 8) // 64B .. ~1MB
 )
 
 // HTTPLogExporter represents the main exporter structure
 type HTTPLogExporter struct {
-	clientset       *kubernetes.Clientset
+	clientset       kubernetes.Interface
 	namespace       string
+	namespaceSelector string
 	scrapeInterval  time.Duration
-	logLines        int64
 	podSelector     string
-	
+	parsers         *ParserRegistry
+	pathNormalizer  *PathNormalizer
+	checkpoints     CheckpointStore
+	activeScrapers  sync.Map // scraperKey -> *activeScraper, one entry per actively-tailed (pod, container)
+
 	// Prometheus metrics
 	httpErrorsTotal *prometheus.CounterVec
 	httpRequestsTotal *prometheus.CounterVec
+	httpRequestDuration *prometheus.HistogramVec
+	httpResponseSize    *prometheus.HistogramVec
 	lastScrapeTime  *prometheus.GaugeVec
 	scrapeErrors    *prometheus.CounterVec
+	targetInfo      *prometheus.GaugeVec
+	droppedLinesTotal *prometheus.CounterVec
+	podsWatched         *prometheus.GaugeVec
+	watchReconnectsTotal *prometheus.CounterVec
+	pathCardinalityCappedTotal *prometheus.CounterVec
+
+	logger *slog.Logger
 }
 
 // LogEntry represents a parsed HTTP log entry
 type LogEntry struct {
 	Timestamp   string
 	Method      string
-	Path        string 
+	Path        string
 	StatusCode  int
 	ResponseSize int
+	DurationSeconds float64
 	UserAgent   string
+	Namespace   string
 	PodName     string
 	ContainerName string
 }
@@ -63,14 +86,20 @@ type LogEntry struct {
 // HTTP log patterns for common log formats
 var (
 	// Combined log format: 127.0.0.1 - - [25/Dec/2019:01:17:21 +0000] "GET /api/health HTTP/1.1" 200 612
-	combinedLogPattern = regexp.MustCompile(`^(\S+) \S+ \S+ \[([^\]]+)\] "(\S+) (\S+) \S+" (\d+) (\d+)`)
-	
+	// with an optional trailing NCSA extended field carrying request duration
+	// in microseconds (%D, e.g. Apache's "%D") or fractional seconds (%T).
+	combinedLogPattern = regexp.MustCompile(`^(\S+) \S+ \S+ \[([^\]]+)\] "(\S+) (\S+) \S+" (\d+) (\d+)(?: (\d+(?:\.\d+)?))?`)
+
 	// Common log format variations
 	commonLogPattern = regexp.MustCompile(`^(\S+) - - \[([^\]]+)\] "(\S+) (\S+) [^"]*" (\d+) (\d+)`)
-	
+
 	// JSON log format (extract status from JSON)
 	jsonLogPattern = regexp.MustCompile(`"status"\s*:\s*(\d+)`)
-	
+
+	// JSON log duration fields, e.g. "duration":1.5 or "latency_ms":42
+	jsonDurationSecondsPattern = regexp.MustCompile(`"duration"\s*:\s*(\d+(?:\.\d+)?)`)
+	jsonLatencyMsPattern       = regexp.MustCompile(`"latency_ms"\s*:\s*(\d+(?:\.\d+)?)`)
+
 	// Simple status code extraction
 	statusCodePattern = regexp.MustCompile(`\b([45]\d{2})\b`)
 )
@@ -84,33 +113,69 @@ func NewHTTPLogExporter() (*HTTPLogExporter, error) {
 
 	// Get configuration from environment variables
 	namespace := getEnvOrDefault(namespaceEnvVar, defaultNamespace)
+	namespaceSelector := os.Getenv(namespaceSelectorEnvVar)
 	scrapeInterval := parseScrapeInterval()
-	logLines := parseLogLines()
 	podSelector := os.Getenv(podSelectorEnvVar)
 
+	parsers, err := loadParsers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load log parsers: %v", err)
+	}
+
+	pathNormalizer, err := loadPathNormalizer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load path normalizer: %v", err)
+	}
+
+	checkpoints, err := newCheckpointStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize checkpoint store: %v", err)
+	}
+
 	return &HTTPLogExporter{
-		clientset:      clientset,
-		namespace:      namespace,
-		scrapeInterval: scrapeInterval,
-		logLines:       logLines,
-		podSelector:    podSelector,
-		
+		clientset:         clientset,
+		namespace:         namespace,
+		namespaceSelector: namespaceSelector,
+		scrapeInterval:    scrapeInterval,
+		podSelector:       podSelector,
+		parsers:           parsers,
+		pathNormalizer:    pathNormalizer,
+		checkpoints:       checkpoints,
+
 		httpErrorsTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "http_errors_total",
 				Help: "Total number of HTTP errors scraped from container logs",
 			},
-			[]string{"namespace", "pod", "container", "status_code", "error_class"},
+			[]string{"namespace", "pod", "container", "status_code", "error_class", "route"},
 		),
-		
+
 		httpRequestsTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
-				Name: "http_requests_total", 
+				Name: "http_requests_total",
 				Help: "Total number of HTTP requests scraped from container logs",
 			},
-			[]string{"namespace", "pod", "container", "status_code"},
+			[]string{"namespace", "pod", "container", "status_code", "route"},
 		),
 		
+		httpRequestDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "http_request_duration_seconds",
+				Help:    "Duration of HTTP requests scraped from container logs",
+				Buckets: parseBuckets(durationBucketsEnvVar, defaultDurationBuckets),
+			},
+			[]string{"namespace", "pod", "container", "method", "status_code"},
+		),
+
+		httpResponseSize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "http_response_size_bytes",
+				Help:    "Size of HTTP responses scraped from container logs",
+				Buckets: parseBuckets(responseSizeBucketsEnvVar, defaultResponseSizeBuckets),
+			},
+			[]string{"namespace", "pod", "container", "method", "status_code"},
+		),
+
 		lastScrapeTime: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "http_log_scraper_last_scrape_timestamp_seconds",
@@ -126,6 +191,48 @@ func NewHTTPLogExporter() (*HTTPLogExporter, error) {
 			},
 			[]string{"namespace", "pod", "container", "error_type"},
 		),
+
+		targetInfo: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "http_log_scraper_target_info",
+				Help: "Set to 1 for each pod currently discovered as a scrape target, labeled with its node",
+			},
+			[]string{"namespace", "pod", "node"},
+		),
+
+		droppedLinesTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "http_log_scraper_dropped_lines_total",
+				Help: "Total number of log lines dropped because the per-container tailer queue was full",
+			},
+			[]string{"namespace", "pod", "container"},
+		),
+
+		podsWatched: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "http_log_scraper_pods_watched",
+				Help: "Number of pods currently tracked as scrape targets by the pod watch",
+			},
+			[]string{"namespace"},
+		),
+
+		watchReconnectsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "http_log_scraper_watch_reconnects_total",
+				Help: "Total number of times the pod watch was re-established after failing or expiring",
+			},
+			[]string{"namespace"},
+		),
+
+		pathCardinalityCappedTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "http_log_scraper_path_cardinality_capped_total",
+				Help: "Total number of requests whose route label was collapsed to the overflow bucket after a container exceeded its route cardinality cap",
+			},
+			[]string{"namespace", "pod", "container"},
+		),
+
+		logger: buildLogger(),
 	}, nil
 }
 
@@ -147,217 +254,191 @@ func parseScrapeInterval() time.Duration {
 	return defaultScrapeInterval
 }
 
-// parseLogLines parses the log lines limit from environment variable
-func parseLogLines() int64 {
-	if linesStr := os.Getenv(logLinesEnvVar); linesStr != "" {
-		if lines, err := strconv.ParseInt(linesStr, 10, 64); err == nil {
-			return lines
-		}
-	}
-	return defaultLogLines
-}
-
 // Describe implements the prometheus.Collector interface
 func (e *HTTPLogExporter) Describe(ch chan<- *prometheus.Desc) {
 	e.httpErrorsTotal.Describe(ch)
 	e.httpRequestsTotal.Describe(ch)
+	e.httpRequestDuration.Describe(ch)
+	e.httpResponseSize.Describe(ch)
 	e.lastScrapeTime.Describe(ch)
 	e.scrapeErrors.Describe(ch)
+	e.targetInfo.Describe(ch)
+	e.droppedLinesTotal.Describe(ch)
+	e.podsWatched.Describe(ch)
+	e.watchReconnectsTotal.Describe(ch)
+	e.pathCardinalityCappedTotal.Describe(ch)
 }
 
 // Collect implements the prometheus.Collector interface
 func (e *HTTPLogExporter) Collect(ch chan<- prometheus.Metric) {
 	e.httpErrorsTotal.Collect(ch)
 	e.httpRequestsTotal.Collect(ch)
+	e.httpRequestDuration.Collect(ch)
+	e.httpResponseSize.Collect(ch)
 	e.lastScrapeTime.Collect(ch)
 	e.scrapeErrors.Collect(ch)
+	e.targetInfo.Collect(ch)
+	e.droppedLinesTotal.Collect(ch)
+	e.podsWatched.Collect(ch)
+	e.watchReconnectsTotal.Collect(ch)
+	e.pathCardinalityCappedTotal.Collect(ch)
 }
 
-// scrapeLogs scrapes logs from all pods in the target namespace
-func (e *HTTPLogExporter) scrapeLogs(ctx context.Context) error {
-	// List pods in the target namespace
-	listOptions := metav1.ListOptions{}
-	if e.podSelector != "" {
-		listOptions.LabelSelector = e.podSelector
-	}
-
-	pods, err := e.clientset.CoreV1().Pods(e.namespace).List(ctx, listOptions)
-	if err != nil {
-		return fmt.Errorf("failed to list pods in namespace %s: %v", e.namespace, err)
-	}
-
-	log.Printf("Found %d pods in namespace %s", len(pods.Items), e.namespace)
-
-	for _, pod := range pods.Items {
-		// Skip pods that are not running
-		if pod.Status.Phase != corev1.PodRunning {
-			continue
-		}
+// parseLogLine attempts to parse a log line and extract HTTP information by
+// running it through e's configured LogParser registry (see parsers.go).
+// Exporters constructed without going through NewHTTPLogExporter (e.g. in
+// tests) fall back to the built-in combined/common/JSON/status parsers.
+func (e *HTTPLogExporter) parseLogLine(line, podName, containerName string) *LogEntry {
+	return e.parseLogLineWithFormat(line, podName, containerName, "")
+}
 
-		for _, container := range pod.Spec.Containers {
-			if err := e.scrapeContainerLogs(ctx, pod.Name, container.Name); err != nil {
-				log.Printf("Error scraping logs from pod %s, container %s: %v", pod.Name, container.Name, err)
-				e.scrapeErrors.WithLabelValues(e.namespace, pod.Name, container.Name, "scrape_failed").Inc()
+// parseLogLineWithFormat is like parseLogLine, but first tries the named
+// format's built-in parser (see format_parsers.go), e.g. one selected via a
+// pod's logFormatAnnotation. An empty format, or one that doesn't match the
+// line, falls back to e's autodetecting parser registry.
+func (e *HTTPLogExporter) parseLogLineWithFormat(line, podName, containerName, format string) *LogEntry {
+	if format != "" {
+		if p, ok := builtinParserByFormat(format); ok {
+			if entry, matched := p.Parse(line); matched {
+				entry.PodName = podName
+				entry.ContainerName = containerName
+				return entry
 			}
 		}
 	}
 
-	return nil
-}
-
-// scrapeContainerLogs scrapes logs from a specific container
-func (e *HTTPLogExporter) scrapeContainerLogs(ctx context.Context, podName, containerName string) error {
-	// Get container logs
-	req := e.clientset.CoreV1().Pods(e.namespace).GetLogs(podName, &corev1.PodLogOptions{
-		Container: containerName,
-		TailLines: &e.logLines,
-		Follow:    false,
-	})
-
-	podLogs, err := req.Stream(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get logs: %v", err)
+	registry := e.parsers
+	if registry == nil {
+		registry = defaultParserRegistry()
 	}
-	defer podLogs.Close()
-
-	// Parse logs line by line
-	scanner := bufio.NewScanner(podLogs)
-	lineCount := 0
-	
-	for scanner.Scan() {
-		line := scanner.Text()
-		lineCount++
-		
-		if entry := e.parseLogLine(line, podName, containerName); entry != nil {
-			e.updateMetrics(entry)
-		}
+	entry := registry.Parse(line, podName, containerName)
+	if entry == nil {
+		e.log().Debug("log line did not match any parser", "pod", podName, "container", containerName)
 	}
+	return entry
+}
 
-	if err := scanner.Err(); err != nil && err != io.EOF {
-		return fmt.Errorf("error reading logs: %v", err)
+// parseTrailingDurationField converts the optional trailing NCSA extended
+// field captured by combinedLogPattern into seconds. A value containing a
+// decimal point is treated as Apache's %T (fractional seconds); a bare
+// integer is treated as %D (microseconds).
+func parseTrailingDurationField(field string) float64 {
+	if strings.Contains(field, ".") {
+		seconds, _ := strconv.ParseFloat(field, 64)
+		return seconds
 	}
-
-	// Update last scrape time
-	e.lastScrapeTime.WithLabelValues(e.namespace, podName, containerName).SetToCurrentTime()
-	
-	log.Printf("Processed %d log lines from pod %s, container %s", lineCount, podName, containerName)
-	return nil
+	microseconds, _ := strconv.ParseFloat(field, 64)
+	return microseconds / 1e6
 }
 
-// parseLogLine attempts to parse a log line and extract HTTP information
-func (e *HTTPLogExporter) parseLogLine(line, podName, containerName string) *LogEntry {
-	// Try different log patterns
-	if matches := combinedLogPattern.FindStringSubmatch(line); matches != nil {
-		statusCode, _ := strconv.Atoi(matches[5])
-		responseSize, _ := strconv.Atoi(matches[6])
-		
-		return &LogEntry{
-			Timestamp:     matches[2],
-			Method:        matches[3],
-			Path:         matches[4],
-			StatusCode:   statusCode,
-			ResponseSize: responseSize,
-			PodName:      podName,
-			ContainerName: containerName,
-		}
+// parseJSONDurationSeconds extracts a request duration in seconds from a
+// JSON log line, preferring an explicit "duration" (seconds) field and
+// falling back to "latency_ms" (milliseconds).
+func parseJSONDurationSeconds(line string) float64 {
+	if matches := jsonDurationSecondsPattern.FindStringSubmatch(line); matches != nil {
+		seconds, _ := strconv.ParseFloat(matches[1], 64)
+		return seconds
 	}
-
-	if matches := commonLogPattern.FindStringSubmatch(line); matches != nil {
-		statusCode, _ := strconv.Atoi(matches[5])
-		responseSize, _ := strconv.Atoi(matches[6])
-		
-		return &LogEntry{
-			Timestamp:     matches[2], 
-			Method:        matches[3],
-			Path:         matches[4], 
-			StatusCode:   statusCode,
-			ResponseSize: responseSize,
-			PodName:      podName,
-			ContainerName: containerName,
-		}
+	if matches := jsonLatencyMsPattern.FindStringSubmatch(line); matches != nil {
+		millis, _ := strconv.ParseFloat(matches[1], 64)
+		return millis / 1000
 	}
+	return 0
+}
 
-	// Try JSON log format
-	if matches := jsonLogPattern.FindStringSubmatch(line); matches != nil {
-		statusCode, _ := strconv.Atoi(matches[1])
-		
-		return &LogEntry{
-			StatusCode:    statusCode,
-			PodName:       podName, 
-			ContainerName: containerName,
-		}
+// parseBuckets parses a comma-separated list of float64 bucket boundaries
+// from an environment variable, falling back to defaults when unset or
+// unparsable.
+func parseBuckets(envVar string, defaults []float64) []float64 {
+	raw := os.Getenv(envVar)
+	if raw == "" {
+		return defaults
 	}
 
-	// Fallback: look for any HTTP status codes
-	if matches := statusCodePattern.FindStringSubmatch(line); matches != nil {
-		statusCode, _ := strconv.Atoi(matches[1])
-		
-		return &LogEntry{
-			StatusCode:    statusCode,
-			PodName:       podName,
-			ContainerName: containerName,
+	parts := strings.Split(raw, ",")
+	buckets := make([]float64, 0, len(parts))
+	for _, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return defaults
 		}
+		buckets = append(buckets, value)
 	}
+	return buckets
+}
 
-	return nil
+// normalizeRoute reduces entry.Path to a low-cardinality route label via
+// e.pathNormalizer, falling back to an empty route when no normalizer is
+// configured (e.g. an HTTPLogExporter built directly in tests). The second
+// return value is true when the per-container cardinality cap collapsed the
+// route to overflowRouteLabel.
+func (e *HTTPLogExporter) normalizeRoute(entry *LogEntry) (string, bool) {
+	if e.pathNormalizer == nil {
+		return "", false
+	}
+	containerKey := pathNormalizerContainerKey(entry.Namespace, entry.PodName, entry.ContainerName)
+	return e.pathNormalizer.Normalize(containerKey, entry.Path)
 }
 
-// updateMetrics updates Prometheus metrics based on the parsed log entry
+// updateMetrics updates Prometheus metrics based on the parsed log entry.
+// The namespace label always comes from entry.Namespace (the pod's actual
+// namespace), not e.namespace, since a single exporter can watch multiple
+// namespaces (see namespaces.go).
 func (e *HTTPLogExporter) updateMetrics(entry *LogEntry) {
 	statusCodeStr := strconv.Itoa(entry.StatusCode)
-	
+	route, capped := e.normalizeRoute(entry)
+	if capped {
+		e.pathCardinalityCappedTotal.WithLabelValues(entry.Namespace, entry.PodName, entry.ContainerName).Inc()
+	}
+
 	// Update total requests counter
 	e.httpRequestsTotal.WithLabelValues(
-		e.namespace,
+		entry.Namespace,
 		entry.PodName,
 		entry.ContainerName,
 		statusCodeStr,
+		route,
 	).Inc()
 
 	// Update error counters for 4xx and 5xx status codes
 	if entry.StatusCode >= 400 && entry.StatusCode < 500 {
 		e.httpErrorsTotal.WithLabelValues(
-			e.namespace,
+			entry.Namespace,
 			entry.PodName,
-			entry.ContainerName, 
+			entry.ContainerName,
 			statusCodeStr,
 			"4xx",
+			route,
 		).Inc()
 	} else if entry.StatusCode >= 500 && entry.StatusCode < 600 {
 		e.httpErrorsTotal.WithLabelValues(
-			e.namespace,
+			entry.Namespace,
 			entry.PodName,
 			entry.ContainerName,
 			statusCodeStr,
-			"5xx", 
+			"5xx",
+			route,
 		).Inc()
 	}
-}
 
-// startPeriodicScraping starts the periodic log scraping in a goroutine
-func (e *HTTPLogExporter) startPeriodicScraping(ctx context.Context) {
-	ticker := time.NewTicker(e.scrapeInterval)
-	defer ticker.Stop()
-
-	// Initial scrape
-	log.Println("Performing initial log scrape...")
-	if err := e.scrapeLogs(ctx); err != nil {
-		log.Printf("Initial scrape failed: %v", err)
+	if entry.DurationSeconds > 0 {
+		e.httpRequestDuration.WithLabelValues(
+			entry.Namespace,
+			entry.PodName,
+			entry.ContainerName,
+			entry.Method,
+			statusCodeStr,
+		).Observe(entry.DurationSeconds)
 	}
 
-	log.Printf("Starting periodic log scraping every %v", e.scrapeInterval)
-	
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("Stopping periodic scraping")
-			return
-		case <-ticker.C:
-			log.Println("Starting scheduled log scrape...")
-			if err := e.scrapeLogs(ctx); err != nil {
-				log.Printf("Scheduled scrape failed: %v", err)
-			}
-		}
+	if entry.ResponseSize > 0 {
+		e.httpResponseSize.WithLabelValues(
+			entry.Namespace,
+			entry.PodName,
+			entry.ContainerName,
+			entry.Method,
+			statusCodeStr,
+		).Observe(float64(entry.ResponseSize))
 	}
 }
 
@@ -365,8 +446,10 @@ func main() {
 	// Create the exporter
 	exporter, err := NewHTTPLogExporter()
 	if err != nil {
-		log.Fatalf("Failed to create HTTP log exporter: %v", err)
+		buildLogger().Error("failed to create HTTP log exporter", "error", err)
+		os.Exit(1)
 	}
+	logger := exporter.logger
 
 	// Create a new Prometheus registry
 	reg := prometheus.NewPedanticRegistry()
@@ -387,19 +470,44 @@ func main() {
 		w.Write([]byte("OK"))
 	})
 
-	// Start periodic scraping in background
+	// Discover scrape targets via the Kubernetes API and start/stop
+	// per-container log tailers as pods come and go.
 	ctx := context.Background()
-	go exporter.startPeriodicScraping(ctx)
+	go func() {
+		if err := exporter.runDiscovery(ctx); err != nil {
+			logger.Error("pod discovery stopped", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	// Optionally accept structured logs pushed directly over OTLP/HTTP,
+	// in addition to the regex-based scraping above.
+	if otlpAddr := os.Getenv(otlpEndpointEnvVar); otlpAddr != "" {
+		otlpMux := http.NewServeMux()
+		NewOTLPLogReceiver(exporter).RegisterHandlers(otlpMux)
+		go func() {
+			logger.Info("OTLP log receiver listening", "address", otlpAddr, "path", otlpLogsPath)
+			if err := http.ListenAndServe(otlpAddr, otlpMux); err != nil {
+				logger.Error("failed to start OTLP log receiver", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
 
 	// Start HTTP server
 	port := getEnvOrDefault("PORT", "8080")
-	log.Printf("HTTP Log Exporter starting on :%s", port)
-	log.Printf("Configuration: namespace=%s, scrapeInterval=%v, logLines=%d, podSelector=%s", 
-		exporter.namespace, exporter.scrapeInterval, exporter.logLines, exporter.podSelector)
-	log.Printf("Metrics available at: http://localhost:%s/metrics", port)
-	log.Printf("Health check available at: http://localhost:%s/health", port)
+	logger.Info("HTTP Log Exporter starting", "port", port)
+	logger.Info("configuration",
+		"namespace", exporter.namespace,
+		"namespaceSelector", exporter.namespaceSelector,
+		"reconnectBackoffCap", exporter.scrapeInterval,
+		"podSelector", exporter.podSelector,
+	)
+	logger.Info("metrics endpoint available", "url", fmt.Sprintf("http://localhost:%s/metrics", port))
+	logger.Info("health check endpoint available", "url", fmt.Sprintf("http://localhost:%s/health", port))
 
 	if err := http.ListenAndServe(":"+port, nil); err != nil {
-		log.Fatalf("Failed to start HTTP server: %v", err)
+		logger.Error("failed to start HTTP server", "error", err)
+		os.Exit(1)
 	}
 } 
\ No newline at end of file
@@ -8,7 +8,7 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_model/go"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -180,86 +180,164 @@ func TestParseLogLine(t *testing.T) {
 	}
 }
 
-func TestUpdateMetrics(t *testing.T) {
-	// Create a test exporter with metrics
-	exporter := &HTTPLogExporter{
-		namespace: "test-namespace",
+func newTestExporter() *HTTPLogExporter {
+	return &HTTPLogExporter{
+		pathNormalizer: NewPathNormalizer(nil, []string{"/api/users/{id}"}, 100),
 		httpErrorsTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "http_errors_total",
 				Help: "Test metric",
 			},
-			[]string{"namespace", "pod", "container", "status_code", "error_class"},
+			[]string{"namespace", "pod", "container", "status_code", "error_class", "route"},
 		),
 		httpRequestsTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "http_requests_total",
 				Help: "Test metric",
 			},
-			[]string{"namespace", "pod", "container", "status_code"},
+			[]string{"namespace", "pod", "container", "status_code", "route"},
+		),
+		httpRequestDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "http_request_duration_seconds",
+				Help:    "Test metric",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"namespace", "pod", "container", "method", "status_code"},
+		),
+		httpResponseSize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "http_response_size_bytes",
+				Help:    "Test metric",
+				Buckets: prometheus.ExponentialBuckets(64, 4, 8),
+			},
+			[]string{"namespace", "pod", "container", "method", "status_code"},
+		),
+		pathCardinalityCappedTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "http_log_scraper_path_cardinality_capped_total",
+				Help: "Test metric",
+			},
+			[]string{"namespace", "pod", "container"},
+		),
+		scrapeErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "http_log_scraper_errors_total",
+				Help: "Test metric",
+			},
+			[]string{"namespace", "pod", "container", "error_type"},
+		),
+		lastScrapeTime: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "http_log_scraper_last_scrape_timestamp_seconds",
+				Help: "Test metric",
+			},
+			[]string{"namespace", "pod", "container"},
+		),
+		droppedLinesTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "http_log_scraper_dropped_lines_total",
+				Help: "Test metric",
+			},
+			[]string{"namespace", "pod", "container"},
 		),
+		checkpoints: newInMemoryCheckpointStore(),
 	}
+}
+
+func TestUpdateMetrics(t *testing.T) {
+	exporter := newTestExporter()
 
 	tests := []struct {
-		name               string
-		entry              *LogEntry
-		expectedRequestInc float64
-		expectedErrorInc   float64
-		expectedErrorClass string
+		name                string
+		entry               *LogEntry
+		expectedRequestInc  float64
+		expectedErrorInc    float64
+		expectedErrorClass  string
+		expectedRoute       string
+		expectedDurationObs uint64
+		expectedSizeObs     uint64
 	}{
 		{
 			name: "200 OK - no error increment",
 			entry: &LogEntry{
 				StatusCode:    200,
+				Namespace:     "test-namespace",
 				PodName:       "test-pod",
 				ContainerName: "app",
 			},
 			expectedRequestInc: 1,
 			expectedErrorInc:   0,
+			expectedRoute:      "",
 		},
 		{
 			name: "404 Not Found - 4xx error",
 			entry: &LogEntry{
 				StatusCode:    404,
+				Namespace:     "test-namespace",
 				PodName:       "test-pod",
 				ContainerName: "app",
 			},
 			expectedRequestInc: 1,
 			expectedErrorInc:   1,
 			expectedErrorClass: "4xx",
+			expectedRoute:      "",
 		},
 		{
 			name: "422 Unprocessable Entity - 4xx error",
 			entry: &LogEntry{
 				StatusCode:    422,
+				Namespace:     "test-namespace",
 				PodName:       "api-pod",
 				ContainerName: "service",
 			},
 			expectedRequestInc: 1,
 			expectedErrorInc:   1,
 			expectedErrorClass: "4xx",
+			expectedRoute:      "",
 		},
 		{
 			name: "500 Internal Server Error - 5xx error",
 			entry: &LogEntry{
 				StatusCode:    500,
+				Namespace:     "test-namespace",
 				PodName:       "backend-pod",
 				ContainerName: "api",
 			},
 			expectedRequestInc: 1,
 			expectedErrorInc:   1,
 			expectedErrorClass: "5xx",
+			expectedRoute:      "",
 		},
 		{
 			name: "503 Service Unavailable - 5xx error",
 			entry: &LogEntry{
 				StatusCode:    503,
+				Namespace:     "test-namespace",
 				PodName:       "service-pod",
 				ContainerName: "backend",
 			},
 			expectedRequestInc: 1,
 			expectedErrorInc:   1,
 			expectedErrorClass: "5xx",
+			expectedRoute:      "",
+		},
+		{
+			name: "200 OK with duration, response size, and a normalized route",
+			entry: &LogEntry{
+				StatusCode:      200,
+				Method:          "GET",
+				Path:            "/api/users/42",
+				DurationSeconds: 0.25,
+				ResponseSize:    2048,
+				Namespace:       "test-namespace",
+				PodName:         "test-pod",
+				ContainerName:   "app",
+			},
+			expectedRequestInc:  1,
+			expectedRoute:       "/api/users/{id}",
+			expectedDurationObs: 1,
+			expectedSizeObs:     1,
 		},
 	}
 
@@ -268,42 +346,108 @@ func TestUpdateMetrics(t *testing.T) {
 			// Reset metrics before test
 			exporter.httpRequestsTotal.Reset()
 			exporter.httpErrorsTotal.Reset()
+			exporter.httpRequestDuration.Reset()
+			exporter.httpResponseSize.Reset()
 
 			// Update metrics
 			exporter.updateMetrics(tt.entry)
 
 			// Check request counter
 			requestMetric := exporter.httpRequestsTotal.WithLabelValues(
-				exporter.namespace,
+				tt.entry.Namespace,
 				tt.entry.PodName,
 				tt.entry.ContainerName,
 				strconv.Itoa(tt.entry.StatusCode), // Convert int to string
+				tt.expectedRoute,
 			)
-			
+
 			// Use a different approach to get the metric value
 			metric := &dto.Metric{}
 			requestMetric.Write(metric)
-			assert.Equal(t, tt.expectedRequestInc, metric.GetCounter().GetValue(), 
+			assert.Equal(t, tt.expectedRequestInc, metric.GetCounter().GetValue(),
 				"Request counter should increment by %f", tt.expectedRequestInc)
 
 			// Check error counter
 			if tt.expectedErrorInc > 0 {
 				errorMetric := exporter.httpErrorsTotal.WithLabelValues(
-					exporter.namespace,
+					tt.entry.Namespace,
 					tt.entry.PodName,
 					tt.entry.ContainerName,
 					strconv.Itoa(tt.entry.StatusCode), // Convert int to string
 					tt.expectedErrorClass,
+					tt.expectedRoute,
 				)
 				errorMetricData := &dto.Metric{}
 				errorMetric.Write(errorMetricData)
 				assert.Equal(t, tt.expectedErrorInc, errorMetricData.GetCounter().GetValue(),
 					"Error counter should increment by %f", tt.expectedErrorInc)
 			}
+
+			// Check duration histogram observation count
+			durationMetric := exporter.httpRequestDuration.WithLabelValues(
+				tt.entry.Namespace,
+				tt.entry.PodName,
+				tt.entry.ContainerName,
+				tt.entry.Method,
+				strconv.Itoa(tt.entry.StatusCode),
+			)
+			durationMetricData := &dto.Metric{}
+			durationMetric.(prometheus.Histogram).Write(durationMetricData)
+			assert.Equal(t, tt.expectedDurationObs, durationMetricData.GetHistogram().GetSampleCount(),
+				"Duration histogram should have %d observation(s)", tt.expectedDurationObs)
+			if tt.expectedDurationObs > 0 {
+				assert.Equal(t, tt.entry.DurationSeconds, durationMetricData.GetHistogram().GetSampleSum())
+			}
+
+			// Check response size histogram observation count
+			sizeMetric := exporter.httpResponseSize.WithLabelValues(
+				tt.entry.Namespace,
+				tt.entry.PodName,
+				tt.entry.ContainerName,
+				tt.entry.Method,
+				strconv.Itoa(tt.entry.StatusCode),
+			)
+			sizeMetricData := &dto.Metric{}
+			sizeMetric.(prometheus.Histogram).Write(sizeMetricData)
+			assert.Equal(t, tt.expectedSizeObs, sizeMetricData.GetHistogram().GetSampleCount(),
+				"Response size histogram should have %d observation(s)", tt.expectedSizeObs)
+			if tt.expectedSizeObs > 0 {
+				assert.Equal(t, float64(tt.entry.ResponseSize), sizeMetricData.GetHistogram().GetSampleSum())
+			}
 		})
 	}
 }
 
+// TestUpdateMetricsCardinalityCap verifies that once a container's route
+// cardinality cap is exceeded, updateMetrics both labels further requests
+// with overflowRouteLabel and increments pathCardinalityCappedTotal.
+func TestUpdateMetricsCardinalityCap(t *testing.T) {
+	exporter := newTestExporter()
+	exporter.pathNormalizer = NewPathNormalizer(nil, nil, 1)
+
+	base := &LogEntry{StatusCode: 200, Namespace: "ns", PodName: "pod", ContainerName: "app"}
+
+	first := *base
+	first.Path = "/a"
+	exporter.updateMetrics(&first)
+
+	second := *base
+	second.Path = "/b"
+	exporter.updateMetrics(&second)
+
+	cappedMetric := exporter.pathCardinalityCappedTotal.WithLabelValues("ns", "pod", "app")
+	cappedMetricData := &dto.Metric{}
+	cappedMetric.Write(cappedMetricData)
+	assert.Equal(t, float64(1), cappedMetricData.GetCounter().GetValue(),
+		"second distinct path beyond the cap should increment pathCardinalityCappedTotal")
+
+	overflowMetric := exporter.httpRequestsTotal.WithLabelValues("ns", "pod", "app", "200", overflowRouteLabel)
+	overflowMetricData := &dto.Metric{}
+	overflowMetric.Write(overflowMetricData)
+	assert.Equal(t, float64(1), overflowMetricData.GetCounter().GetValue(),
+		"the capped request should be labeled with overflowRouteLabel")
+}
+
 func TestGetEnvOrDefault(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -404,61 +548,6 @@ func TestParseScrapeInterval(t *testing.T) {
 	}
 }
 
-func TestParseLogLines(t *testing.T) {
-	tests := []struct {
-		name     string
-		envValue string
-		expected int64
-		setEnv   bool
-	}{
-		{
-			name:     "Valid lines value",
-			envValue: "500",
-			expected: 500,
-			setEnv:   true,
-		},
-		{
-			name:     "Invalid value",
-			envValue: "invalid",
-			expected: defaultLogLines,
-			setEnv:   true,
-		},
-		{
-			name:     "Environment variable not set",
-			expected: defaultLogLines,
-			setEnv:   false,
-		},
-		{
-			name:     "Zero value",
-			envValue: "0",
-			expected: 0,
-			setEnv:   true,
-		},
-		{
-			name:     "Negative value",
-			envValue: "-10",
-			expected: -10,
-			setEnv:   true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Clean up environment variable
-			defer func() {
-				os.Unsetenv(logLinesEnvVar)
-			}()
-
-			if tt.setEnv {
-				os.Setenv(logLinesEnvVar, tt.envValue)
-			}
-
-			result := parseLogLines()
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
 func TestLogPatterns(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -581,6 +670,90 @@ func TestLogEntryCreation(t *testing.T) {
 	assert.Equal(t, "app-container", entry.ContainerName)
 }
 
+func TestParseLogLineDuration(t *testing.T) {
+	exporter := &HTTPLogExporter{}
+
+	tests := []struct {
+		name            string
+		line            string
+		expectedSeconds float64
+	}{
+		{
+			name:            "Combined log with %D microseconds",
+			line:            `127.0.0.1 - - [25/Dec/2019:01:17:21 +0000] "GET /api/health HTTP/1.1" 200 612 150000`,
+			expectedSeconds: 0.15,
+		},
+		{
+			name:            "Combined log with %T fractional seconds",
+			line:            `127.0.0.1 - - [25/Dec/2019:01:17:21 +0000] "GET /api/health HTTP/1.1" 200 612 0.25`,
+			expectedSeconds: 0.25,
+		},
+		{
+			name:            "Combined log without trailing duration field",
+			line:            `127.0.0.1 - - [25/Dec/2019:01:17:21 +0000] "GET /api/health HTTP/1.1" 200 612`,
+			expectedSeconds: 0,
+		},
+		{
+			name:            "JSON log with duration field in seconds",
+			line:            `{"status":200,"duration":0.42}`,
+			expectedSeconds: 0.42,
+		},
+		{
+			name:            "JSON log with latency_ms field",
+			line:            `{"status":200,"latency_ms":120}`,
+			expectedSeconds: 0.12,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := exporter.parseLogLine(tt.line, "test-pod", "app")
+			assert.NotNil(t, result)
+			assert.InDelta(t, tt.expectedSeconds, result.DurationSeconds, 0.0001)
+		})
+	}
+}
+
+func TestParseBuckets(t *testing.T) {
+	tests := []struct {
+		name     string
+		envValue string
+		setEnv   bool
+		expected []float64
+	}{
+		{
+			name:     "Valid custom buckets",
+			envValue: "0.1,0.5,1,5",
+			setEnv:   true,
+			expected: []float64{0.1, 0.5, 1, 5},
+		},
+		{
+			name:     "Invalid value falls back to defaults",
+			envValue: "not-a-number",
+			setEnv:   true,
+			expected: []float64{1, 2, 3},
+		},
+		{
+			name:     "Environment variable not set",
+			setEnv:   false,
+			expected: []float64{1, 2, 3},
+		},
+	}
+
+	const testBucketsEnvVar = "TEST_BUCKETS_ENV_VAR"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer os.Unsetenv(testBucketsEnvVar)
+			if tt.setEnv {
+				os.Setenv(testBucketsEnvVar, tt.envValue)
+			}
+
+			result := parseBuckets(testBucketsEnvVar, []float64{1, 2, 3})
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
 // Benchmark tests for performance
 func BenchmarkParseLogLine(b *testing.B) {
 	exporter := &HTTPLogExporter{}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+const (
+	// logLevelEnvVar selects the minimum level logged: "debug", "info"
+	// (default), "warn", or "error".
+	logLevelEnvVar = "LOG_LEVEL"
+
+	// logOutputFormatEnvVar selects the exporter's own log encoding:
+	// "json", or the default plain logfmt-style text.
+	logOutputFormatEnvVar = "LOG_FORMAT"
+)
+
+// buildLogger constructs the exporter's slog.Logger from LOG_LEVEL and
+// LOG_FORMAT, so the exporter's own logs can be correlated with (and
+// collected the same way as) the structured application logs it scrapes.
+func buildLogger() *slog.Logger {
+	opts := &slog.HandlerOptions{Level: parseLogLevel(os.Getenv(logLevelEnvVar))}
+
+	var handler slog.Handler
+	if strings.EqualFold(os.Getenv(logOutputFormatEnvVar), "json") {
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	} else {
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	}
+	return slog.New(handler)
+}
+
+// parseLogLevel maps a LOG_LEVEL value onto a slog.Level, defaulting to info
+// for an unset or unrecognized value.
+func parseLogLevel(raw string) slog.Level {
+	switch strings.ToLower(raw) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// log returns e.logger, falling back to a discard logger for HTTPLogExporters
+// built directly rather than via NewHTTPLogExporter (e.g. in tests).
+func (e *HTTPLogExporter) log() *slog.Logger {
+	if e.logger != nil {
+		return e.logger
+	}
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
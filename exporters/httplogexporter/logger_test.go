@@ -0,0 +1,35 @@
+package main
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected slog.Level
+	}{
+		{name: "debug", raw: "debug", expected: slog.LevelDebug},
+		{name: "warn", raw: "warn", expected: slog.LevelWarn},
+		{name: "warning alias", raw: "warning", expected: slog.LevelWarn},
+		{name: "error", raw: "error", expected: slog.LevelError},
+		{name: "uppercase is case-insensitive", raw: "ERROR", expected: slog.LevelError},
+		{name: "empty defaults to info", raw: "", expected: slog.LevelInfo},
+		{name: "unrecognized defaults to info", raw: "trace", expected: slog.LevelInfo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseLogLevel(tt.raw))
+		})
+	}
+}
+
+func TestHTTPLogExporterLogFallsBackWhenNil(t *testing.T) {
+	exporter := &HTTPLogExporter{}
+	assert.NotNil(t, exporter.log())
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// namespaceSelectorEnvVar, if set, switches the exporter from a fixed
+// namespace list (namespaceEnvVar) to cluster-scoped mode: it watches
+// Namespaces matching this label selector and fans pod discovery out across
+// whichever namespaces currently match, as they come and go. Requires the
+// exporter's ServiceAccount to be cluster-scoped (see RBAC docs below).
+const namespaceSelectorEnvVar = "NAMESPACE_SELECTOR"
+
+// parseTargetNamespaces splits a comma-separated TARGET_NAMESPACE value into
+// a trimmed, non-empty list of namespace names. An empty value yields
+// []string{defaultNamespace}, preserving the exporter's original
+// single-namespace default.
+func parseTargetNamespaces(raw string) []string {
+	parts := strings.Split(raw, ",")
+	namespaces := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if name := strings.TrimSpace(part); name != "" {
+			namespaces = append(namespaces, name)
+		}
+	}
+	if len(namespaces) == 0 {
+		return []string{defaultNamespace}
+	}
+	return namespaces
+}
+
+// NamespaceDiscovery watches cluster-scoped Namespaces matching
+// labelSelector, invoking OnNamespaceAdded/OnNamespaceRemoved as matching
+// namespaces come and go. This lets runClusterScopedDiscovery fan pod
+// discovery out dynamically instead of over a fixed namespace list.
+//
+// Cluster-scoped mode needs a ClusterRole (bound to the exporter's
+// ServiceAccount via a ClusterRoleBinding) instead of the namespaced Role
+// that suffices for a fixed TARGET_NAMESPACE list, since it lists/watches
+// Namespaces and Pods across the whole cluster:
+//
+//	apiVersion: rbac.authorization.k8s.io/v1
+//	kind: ClusterRole
+//	metadata:
+//	  name: http-log-exporter
+//	rules:
+//	- apiGroups: [""]
+//	  resources: ["namespaces"]
+//	  verbs: ["list", "watch"]
+//	- apiGroups: [""]
+//	  resources: ["pods", "pods/log"]
+//	  verbs: ["get", "list", "watch"]
+type NamespaceDiscovery struct {
+	clientset     kubernetes.Interface
+	labelSelector string
+
+	OnNamespaceAdded   func(namespace string)
+	OnNamespaceRemoved func(namespace string)
+}
+
+// NewNamespaceDiscovery creates a NamespaceDiscovery restricted to namespaces
+// matching labelSelector (e.g. "team=payments").
+func NewNamespaceDiscovery(clientset kubernetes.Interface, labelSelector string) *NamespaceDiscovery {
+	return &NamespaceDiscovery{clientset: clientset, labelSelector: labelSelector}
+}
+
+// Run starts the namespace informer and blocks processing events until ctx
+// is canceled.
+func (d *NamespaceDiscovery) Run(ctx context.Context) error {
+	listWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.LabelSelector = d.labelSelector
+			return d.clientset.CoreV1().Namespaces().List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.LabelSelector = d.labelSelector
+			return d.clientset.CoreV1().Namespaces().Watch(ctx, options)
+		},
+	}
+
+	_, informer := cache.NewInformer(listWatch, &corev1.Namespace{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			ns, ok := obj.(*corev1.Namespace)
+			if !ok || d.OnNamespaceAdded == nil {
+				return
+			}
+			d.OnNamespaceAdded(ns.Name)
+		},
+		DeleteFunc: func(obj interface{}) {
+			ns, ok := obj.(*corev1.Namespace)
+			if !ok {
+				tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					return
+				}
+				ns, ok = tombstone.Obj.(*corev1.Namespace)
+				if !ok {
+					return
+				}
+			}
+			if d.OnNamespaceRemoved != nil {
+				d.OnNamespaceRemoved(ns.Name)
+			}
+		},
+	})
+
+	informer.Run(ctx.Done())
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTargetNamespaces(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected []string
+	}{
+		{
+			name:     "empty defaults to defaultNamespace",
+			raw:      "",
+			expected: []string{defaultNamespace},
+		},
+		{
+			name:     "single namespace",
+			raw:      "payments",
+			expected: []string{"payments"},
+		},
+		{
+			name:     "comma-separated list",
+			raw:      "payments,checkout,billing",
+			expected: []string{"payments", "checkout", "billing"},
+		},
+		{
+			name:     "whitespace and empty entries are trimmed and dropped",
+			raw:      " payments ,, checkout ",
+			expected: []string{"payments", "checkout"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseTargetNamespaces(tt.raw))
+		})
+	}
+}
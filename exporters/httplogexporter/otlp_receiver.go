@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// otlpLogsPath is the HTTP path the OTLP/HTTP logs exporter in the
+	// OpenTelemetry Collector posts ExportLogsServiceRequest payloads to.
+	otlpLogsPath = "/v1/logs"
+
+	// otlpEndpointEnvVar configures the listen address for the OTLP receiver.
+	// Leaving it unset disables the receiver entirely.
+	otlpEndpointEnvVar = "OTLP_HTTP_ENDPOINT"
+)
+
+// Well-known OTLP log record attribute keys this receiver understands.
+const (
+	attrHTTPStatusCode    = "http.status_code"
+	attrHTTPMethod        = "http.method"
+	attrHTTPRoute         = "http.route"
+	attrHTTPDuration      = "http.duration_seconds"
+	attrHTTPResponseSize  = "http.response_size_bytes"
+	attrK8sPodName        = "k8s.pod.name"
+	attrK8sContainer      = "k8s.container.name"
+	attrK8sNamespace      = "k8s.namespace.name"
+)
+
+// OTLPLogReceiver accepts OTLP/HTTP log exports (protobuf or JSON encoded
+// ExportLogsServiceRequest) and feeds the decoded records into the same
+// updateMetrics path used by the regex-based container log scraper. This
+// lets users who already run the OpenTelemetry Collector push logs in
+// directly instead of relying on kubelet log tailing.
+type OTLPLogReceiver struct {
+	exporter *HTTPLogExporter
+}
+
+// NewOTLPLogReceiver creates a receiver that records decoded log entries
+// against e.
+func NewOTLPLogReceiver(e *HTTPLogExporter) *OTLPLogReceiver {
+	return &OTLPLogReceiver{exporter: e}
+}
+
+// RegisterHandlers wires the receiver into mux at otlpLogsPath.
+func (r *OTLPLogReceiver) RegisterHandlers(mux *http.ServeMux) {
+	mux.HandleFunc(otlpLogsPath, r.handleExport)
+}
+
+func (r *OTLPLogReceiver) handleExport(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	defer req.Body.Close()
+
+	exportReq := &colmetricspb.ExportLogsServiceRequest{}
+
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		if err := protojson.Unmarshal(body, exportReq); err != nil {
+			http.Error(w, fmt.Sprintf("failed to decode OTLP/JSON logs request: %v", err), http.StatusBadRequest)
+			return
+		}
+	default:
+		// application/x-protobuf is the default per the OTLP/HTTP spec.
+		if err := proto.Unmarshal(body, exportReq); err != nil {
+			http.Error(w, fmt.Sprintf("failed to decode OTLP/protobuf logs request: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	count := r.processExportRequest(exportReq)
+	r.exporter.log().Info("OTLP receiver ingested log records", "count", count)
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	if req.Header.Get("Content-Type") == "application/json" {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+		return
+	}
+	resp, _ := proto.Marshal(&colmetricspb.ExportLogsServiceResponse{})
+	w.Write(resp)
+}
+
+// processExportRequest decodes every LogRecord in req into a LogEntry and
+// feeds it through updateMetrics, mirroring the regex scraping path. It
+// returns the number of records processed.
+func (r *OTLPLogReceiver) processExportRequest(req *colmetricspb.ExportLogsServiceRequest) int {
+	count := 0
+	for _, rl := range req.GetResourceLogs() {
+		resourceAttrs := attributesToMap(rl.GetResource().GetAttributes())
+
+		for _, sl := range rl.GetScopeLogs() {
+			for _, record := range sl.GetLogRecords() {
+				entry := r.logRecordToEntry(record, resourceAttrs)
+				if entry == nil {
+					continue
+				}
+				r.exporter.updateMetrics(entry)
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// logRecordToEntry maps an OTLP LogRecord's attributes directly onto a
+// LogEntry, without any regex parsing; the record's body is not read.
+// Record-level attributes take precedence over resource-level attributes of
+// the same name.
+func (r *OTLPLogReceiver) logRecordToEntry(record *logspb.LogRecord, resourceAttrs map[string]string) *LogEntry {
+	attrs := attributesToMap(record.GetAttributes())
+	for k, v := range resourceAttrs {
+		if _, ok := attrs[k]; !ok {
+			attrs[k] = v
+		}
+	}
+
+	statusCode, err := strconv.Atoi(attrs[attrHTTPStatusCode])
+	if err != nil {
+		// Without a status code there is nothing for updateMetrics to key on.
+		return nil
+	}
+
+	namespace := attrs[attrK8sNamespace]
+	podName := attrs[attrK8sPodName]
+	containerName := attrs[attrK8sContainer]
+	if namespace == "" {
+		namespace = "unknown"
+	}
+	if podName == "" {
+		podName = "unknown"
+	}
+	if containerName == "" {
+		containerName = "unknown"
+	}
+
+	entry := &LogEntry{
+		Method:        attrs[attrHTTPMethod],
+		Path:          attrs[attrHTTPRoute],
+		StatusCode:    statusCode,
+		Namespace:     namespace,
+		PodName:       podName,
+		ContainerName: containerName,
+	}
+	if duration, err := strconv.ParseFloat(attrs[attrHTTPDuration], 64); err == nil {
+		entry.DurationSeconds = duration
+	}
+	if size, err := strconv.Atoi(attrs[attrHTTPResponseSize]); err == nil {
+		entry.ResponseSize = size
+	}
+	return entry
+}
+
+// attributesToMap flattens OTLP KeyValue attributes into a string map,
+// using the string representation of whichever value type is set.
+func attributesToMap(attrs []*commonpb.KeyValue) map[string]string {
+	out := make(map[string]string, len(attrs))
+	for _, kv := range attrs {
+		out[kv.GetKey()] = anyValueToString(kv.GetValue())
+	}
+	return out
+}
+
+func anyValueToString(v *commonpb.AnyValue) string {
+	switch val := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return val.StringValue
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(val.IntValue, 10)
+	case *commonpb.AnyValue_DoubleValue:
+		return strconv.FormatFloat(val.DoubleValue, 'f', -1, 64)
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(val.BoolValue)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+}
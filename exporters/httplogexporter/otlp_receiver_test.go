@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func stringAttr(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+func intAttr(key string, value int64) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: value}},
+	}
+}
+
+func doubleAttr(key string, value float64) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: value}},
+	}
+}
+
+func boolAttr(key string, value bool) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: value}},
+	}
+}
+
+func TestAttributesToMap(t *testing.T) {
+	attrs := []*commonpb.KeyValue{
+		stringAttr("http.method", "GET"),
+		intAttr("http.status_code", 200),
+		doubleAttr("http.duration_seconds", 0.5),
+		boolAttr("http.retried", true),
+	}
+
+	got := attributesToMap(attrs)
+
+	assert.Equal(t, "GET", got["http.method"])
+	assert.Equal(t, "200", got["http.status_code"])
+	assert.Equal(t, "0.5", got["http.duration_seconds"])
+	assert.Equal(t, "true", got["http.retried"])
+}
+
+func TestLogRecordToEntryRecordAttributesTakePrecedence(t *testing.T) {
+	r := NewOTLPLogReceiver(&HTTPLogExporter{})
+
+	resourceAttrs := map[string]string{
+		attrK8sNamespace: "resource-ns",
+		attrK8sPodName:   "resource-pod",
+	}
+	record := &logspb.LogRecord{
+		Attributes: []*commonpb.KeyValue{
+			stringAttr(attrHTTPStatusCode, "200"),
+			stringAttr(attrHTTPMethod, "GET"),
+			stringAttr(attrHTTPRoute, "/api/health"),
+			stringAttr(attrK8sNamespace, "record-ns"),
+			stringAttr(attrK8sContainer, "app"),
+			stringAttr(attrHTTPDuration, "0.25"),
+			stringAttr(attrHTTPResponseSize, "1024"),
+		},
+	}
+
+	entry := r.logRecordToEntry(record, resourceAttrs)
+
+	assert.NotNil(t, entry)
+	assert.Equal(t, 200, entry.StatusCode)
+	assert.Equal(t, "GET", entry.Method)
+	assert.Equal(t, "/api/health", entry.Path)
+	// Record-level k8s.namespace.name overrides the resource-level one.
+	assert.Equal(t, "record-ns", entry.Namespace)
+	// k8s.pod.name only appears at the resource level, so it falls through.
+	assert.Equal(t, "resource-pod", entry.PodName)
+	assert.Equal(t, "app", entry.ContainerName)
+	assert.InDelta(t, 0.25, entry.DurationSeconds, 1e-9)
+	assert.Equal(t, 1024, entry.ResponseSize)
+}
+
+func TestLogRecordToEntryDefaultsMissingK8sAttrsToUnknown(t *testing.T) {
+	r := NewOTLPLogReceiver(&HTTPLogExporter{})
+
+	record := &logspb.LogRecord{
+		Attributes: []*commonpb.KeyValue{
+			stringAttr(attrHTTPStatusCode, "500"),
+		},
+	}
+
+	entry := r.logRecordToEntry(record, nil)
+
+	assert.NotNil(t, entry)
+	assert.Equal(t, "unknown", entry.Namespace)
+	assert.Equal(t, "unknown", entry.PodName)
+	assert.Equal(t, "unknown", entry.ContainerName)
+}
+
+func TestLogRecordToEntryMissingStatusCodeReturnsNil(t *testing.T) {
+	r := NewOTLPLogReceiver(&HTTPLogExporter{})
+
+	record := &logspb.LogRecord{
+		Attributes: []*commonpb.KeyValue{
+			stringAttr(attrHTTPMethod, "GET"),
+		},
+	}
+
+	assert.Nil(t, r.logRecordToEntry(record, nil))
+}
+
+func TestProcessExportRequest(t *testing.T) {
+	exporter := newTestExporter()
+	r := NewOTLPLogReceiver(exporter)
+
+	req := &colmetricspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{
+			{
+				Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+					stringAttr(attrK8sNamespace, "ns"),
+					stringAttr(attrK8sPodName, "pod"),
+					stringAttr(attrK8sContainer, "app"),
+				}},
+				ScopeLogs: []*logspb.ScopeLogs{
+					{
+						LogRecords: []*logspb.LogRecord{
+							{Attributes: []*commonpb.KeyValue{stringAttr(attrHTTPStatusCode, "200")}},
+							// Missing status code: should be skipped, not counted.
+							{Attributes: []*commonpb.KeyValue{stringAttr(attrHTTPMethod, "GET")}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	count := r.processExportRequest(req)
+	assert.Equal(t, 1, count)
+
+	metric := exporter.httpRequestsTotal.WithLabelValues("ns", "pod", "app", "200", "")
+	data := &dto.Metric{}
+	metric.Write(data)
+	assert.Equal(t, float64(1), data.GetCounter().GetValue())
+}
+
+func TestHandleExportJSON(t *testing.T) {
+	exporter := newTestExporter()
+	receiver := NewOTLPLogReceiver(exporter)
+	mux := http.NewServeMux()
+	receiver.RegisterHandlers(mux)
+
+	exportReq := &colmetricspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{
+			{
+				ScopeLogs: []*logspb.ScopeLogs{
+					{
+						LogRecords: []*logspb.LogRecord{
+							{Attributes: []*commonpb.KeyValue{
+								stringAttr(attrHTTPStatusCode, "200"),
+								stringAttr(attrK8sNamespace, "ns"),
+								stringAttr(attrK8sPodName, "pod"),
+								stringAttr(attrK8sContainer, "app"),
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+	body, err := protojson.Marshal(exportReq)
+	assert.NoError(t, err)
+
+	httpReq := httptest.NewRequest(http.MethodPost, otlpLogsPath, bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	mux.ServeHTTP(rec, httpReq)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	metric := exporter.httpRequestsTotal.WithLabelValues("ns", "pod", "app", "200", "")
+	data := &dto.Metric{}
+	metric.Write(data)
+	assert.Equal(t, float64(1), data.GetCounter().GetValue())
+}
+
+func TestHandleExportProtobuf(t *testing.T) {
+	exporter := newTestExporter()
+	receiver := NewOTLPLogReceiver(exporter)
+	mux := http.NewServeMux()
+	receiver.RegisterHandlers(mux)
+
+	exportReq := &colmetricspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{
+			{
+				ScopeLogs: []*logspb.ScopeLogs{
+					{
+						LogRecords: []*logspb.LogRecord{
+							{Attributes: []*commonpb.KeyValue{
+								stringAttr(attrHTTPStatusCode, "404"),
+								stringAttr(attrK8sNamespace, "ns"),
+								stringAttr(attrK8sPodName, "pod"),
+								stringAttr(attrK8sContainer, "app"),
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+	body, err := proto.Marshal(exportReq)
+	assert.NoError(t, err)
+
+	httpReq := httptest.NewRequest(http.MethodPost, otlpLogsPath, bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	rec := httptest.NewRecorder()
+
+	mux.ServeHTTP(rec, httpReq)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/x-protobuf", rec.Header().Get("Content-Type"))
+
+	metric := exporter.httpRequestsTotal.WithLabelValues("ns", "pod", "app", "404", "")
+	data := &dto.Metric{}
+	metric.Write(data)
+	assert.Equal(t, float64(1), data.GetCounter().GetValue())
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parserConfigPathEnvVar points at a YAML (or JSON) file, typically mounted
+// from a ConfigMap, describing additional LogParsers to register alongside
+// the built-in combined/common/JSON/fallback parsers.
+const parserConfigPathEnvVar = "LOG_PARSER_CONFIG_PATH"
+
+// LogParser turns a raw container log line into a LogEntry. Registered
+// parsers are tried in priority order by parseLogLine; the first one that
+// matches wins.
+type LogParser interface {
+	// Name identifies the parser, primarily for logging/debugging.
+	Name() string
+	// Parse attempts to extract a LogEntry from line. The returned bool
+	// reports whether the parser recognized the line at all.
+	Parse(line string) (*LogEntry, bool)
+}
+
+// ParserRegistry holds an ordered set of LogParsers, highest priority first.
+type ParserRegistry struct {
+	parsers []LogParser
+}
+
+// NewParserRegistry returns an empty registry; parsers are tried in the
+// order they are registered. Built-ins are registered by
+// defaultParserRegistry, and loadParsers registers user-defined parsers
+// loaded from config ahead of them.
+func NewParserRegistry() *ParserRegistry {
+	return &ParserRegistry{}
+}
+
+// Register adds a parser to the registry. Parsers registered earlier take
+// precedence over ones registered later.
+func (r *ParserRegistry) Register(p LogParser) {
+	r.parsers = append(r.parsers, p)
+}
+
+// Parse runs line through every registered parser in order, returning the
+// first match.
+func (r *ParserRegistry) Parse(line, podName, containerName string) *LogEntry {
+	for _, p := range r.parsers {
+		if entry, ok := p.Parse(line); ok {
+			entry.PodName = podName
+			entry.ContainerName = containerName
+			return entry
+		}
+	}
+	return nil
+}
+
+// regexLogParser maps named regex capture groups onto LogEntry fields.
+// fields maps LogEntry field names ("timestamp", "method", "path",
+// "status", "size", "duration") to 1-based capture group indexes.
+type regexLogParser struct {
+	name    string
+	pattern *regexp.Regexp
+	fields  map[string]int
+}
+
+func (p *regexLogParser) Name() string { return p.name }
+
+func (p *regexLogParser) Parse(line string) (*LogEntry, bool) {
+	matches := p.pattern.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, false
+	}
+
+	entry := &LogEntry{}
+	if idx, ok := p.fields["timestamp"]; ok && idx < len(matches) {
+		entry.Timestamp = matches[idx]
+	}
+	if idx, ok := p.fields["method"]; ok && idx < len(matches) {
+		entry.Method = matches[idx]
+	}
+	if idx, ok := p.fields["path"]; ok && idx < len(matches) {
+		entry.Path = matches[idx]
+	}
+	if idx, ok := p.fields["status"]; ok && idx < len(matches) {
+		entry.StatusCode, _ = strconv.Atoi(matches[idx])
+	}
+	if idx, ok := p.fields["size"]; ok && idx < len(matches) {
+		entry.ResponseSize, _ = strconv.Atoi(matches[idx])
+	}
+	if idx, ok := p.fields["duration"]; ok && idx < len(matches) && matches[idx] != "" {
+		entry.DurationSeconds = parseTrailingDurationField(matches[idx])
+	}
+
+	return entry, true
+}
+
+// jsonFieldLogParser decodes the line as a JSON object and plucks fields
+// out by dot-path, e.g. "response.status_code".
+type jsonFieldLogParser struct {
+	name   string
+	fields map[string]string // LogEntry field name -> JSON dot-path
+}
+
+func (p *jsonFieldLogParser) Name() string { return p.name }
+
+func (p *jsonFieldLogParser) Parse(line string) (*LogEntry, bool) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &doc); err != nil {
+		return nil, false
+	}
+
+	statusPath, ok := p.fields["status"]
+	if !ok {
+		return nil, false
+	}
+	statusVal, found := jsonFieldPath(doc, statusPath)
+	if !found {
+		return nil, false
+	}
+
+	entry := &LogEntry{StatusCode: toInt(statusVal)}
+	if path, ok := p.fields["method"]; ok {
+		if v, found := jsonFieldPath(doc, path); found {
+			entry.Method = fmt.Sprintf("%v", v)
+		}
+	}
+	if path, ok := p.fields["path"]; ok {
+		if v, found := jsonFieldPath(doc, path); found {
+			entry.Path = fmt.Sprintf("%v", v)
+		}
+	}
+	if path, ok := p.fields["size"]; ok {
+		if v, found := jsonFieldPath(doc, path); found {
+			entry.ResponseSize = toInt(v)
+		}
+	}
+	if path, ok := p.fields["user_agent"]; ok {
+		if v, found := jsonFieldPath(doc, path); found {
+			entry.UserAgent = fmt.Sprintf("%v", v)
+		}
+	}
+	if path, ok := p.fields["duration"]; ok {
+		if v, found := jsonFieldPath(doc, path); found {
+			entry.DurationSeconds = toFloat(v)
+		}
+	} else if path, ok := p.fields["duration_ms"]; ok {
+		if v, found := jsonFieldPath(doc, path); found {
+			entry.DurationSeconds = toFloat(v) / 1000
+		}
+	}
+
+	return entry, true
+}
+
+// jsonFieldPath resolves a dot-separated path (e.g. "response.status_code")
+// against a decoded JSON document.
+func jsonFieldPath(doc map[string]interface{}, path string) (interface{}, bool) {
+	segments := strings.Split(path, ".")
+	var current interface{} = doc
+	for _, segment := range segments {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[segment]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func toInt(v interface{}) int {
+	switch val := v.(type) {
+	case int:
+		return val
+	case float64:
+		return int(val)
+	case string:
+		n, _ := strconv.Atoi(val)
+		return n
+	default:
+		return 0
+	}
+}
+
+func toFloat(v interface{}) float64 {
+	switch val := v.(type) {
+	case float64:
+		return val
+	case string:
+		f, _ := strconv.ParseFloat(val, 64)
+		return f
+	default:
+		return 0
+	}
+}
+
+// ParserConfig is the on-disk (YAML or JSON) shape for user-defined
+// parsers, typically mounted into the pod as a ConfigMap.
+type ParserConfig struct {
+	Parsers []ParserConfigEntry `yaml:"parsers" json:"parsers"`
+}
+
+// ParserConfigEntry describes a single user-defined parser. Type is either
+// "regex" (Pattern + Fields mapping to capture group indexes) or "json"
+// (Fields mapping to dot-paths into the decoded JSON document). Priority
+// determines ordering among configured parsers, highest first; it does
+// not affect the built-in parsers, which always run last as a fallback.
+type ParserConfigEntry struct {
+	Name     string         `yaml:"name" json:"name"`
+	Type     string         `yaml:"type" json:"type"`
+	Priority int            `yaml:"priority" json:"priority"`
+	Pattern  string         `yaml:"pattern" json:"pattern"`
+	Fields   map[string]interface{} `yaml:"fields" json:"fields"`
+}
+
+// LoadParserConfig reads and parses a parser config file. YAML and JSON are
+// both accepted since JSON is a subset of YAML.
+func LoadParserConfig(path string) (*ParserConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parser config %s: %v", path, err)
+	}
+
+	var cfg ParserConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse parser config %s: %v", path, err)
+	}
+	return &cfg, nil
+}
+
+// buildConfiguredParsers converts config entries into LogParsers, ordered
+// by descending priority.
+func buildConfiguredParsers(cfg *ParserConfig) ([]LogParser, error) {
+	entries := append([]ParserConfigEntry(nil), cfg.Parsers...)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Priority > entries[j].Priority })
+
+	parsers := make([]LogParser, 0, len(entries))
+	for _, entry := range entries {
+		switch entry.Type {
+		case "regex":
+			pattern, err := regexp.Compile(entry.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("parser %q: invalid pattern: %v", entry.Name, err)
+			}
+			fields := make(map[string]int, len(entry.Fields))
+			for field, group := range entry.Fields {
+				fields[field] = toInt(group)
+			}
+			parsers = append(parsers, &regexLogParser{name: entry.Name, pattern: pattern, fields: fields})
+
+		case "json":
+			fields := make(map[string]string, len(entry.Fields))
+			for field, path := range entry.Fields {
+				fields[field] = fmt.Sprintf("%v", path)
+			}
+			parsers = append(parsers, &jsonFieldLogParser{name: entry.Name, fields: fields})
+
+		default:
+			return nil, fmt.Errorf("parser %q: unknown type %q (want \"regex\" or \"json\")", entry.Name, entry.Type)
+		}
+	}
+	return parsers, nil
+}
+
+// defaultParserRegistry wraps the original hard-coded combined/common/JSON
+// status/fallback patterns as LogParsers, preserving prior behavior for
+// anyone not using a parser config file.
+func defaultParserRegistry() *ParserRegistry {
+	reg := NewParserRegistry()
+	reg.Register(&regexLogParser{
+		name:    "combined",
+		pattern: combinedLogPattern,
+		fields:  map[string]int{"timestamp": 2, "method": 3, "path": 4, "status": 5, "size": 6, "duration": 7},
+	})
+	reg.Register(&regexLogParser{
+		name:    "common",
+		pattern: commonLogPattern,
+		fields:  map[string]int{"timestamp": 2, "method": 3, "path": 4, "status": 5, "size": 6},
+	})
+	reg.Register(&jsonStatusLogParser{})
+	reg.Register(&statusCodeFallbackLogParser{})
+	return reg
+}
+
+// jsonStatusLogParser recovers the original generic "status" field scrape
+// that doesn't require a full JSON document shape (e.g. truncated lines).
+type jsonStatusLogParser struct{}
+
+func (jsonStatusLogParser) Name() string { return "json-status-fallback" }
+
+func (jsonStatusLogParser) Parse(line string) (*LogEntry, bool) {
+	matches := jsonLogPattern.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, false
+	}
+	statusCode, _ := strconv.Atoi(matches[1])
+	return &LogEntry{StatusCode: statusCode, DurationSeconds: parseJSONDurationSeconds(line)}, true
+}
+
+// statusCodeFallbackLogParser is the last-resort "find any 4xx/5xx number"
+// parser used when nothing more structured matched.
+type statusCodeFallbackLogParser struct{}
+
+func (statusCodeFallbackLogParser) Name() string { return "status-code-fallback" }
+
+func (statusCodeFallbackLogParser) Parse(line string) (*LogEntry, bool) {
+	matches := statusCodePattern.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, false
+	}
+	statusCode, _ := strconv.Atoi(matches[1])
+	return &LogEntry{StatusCode: statusCode}, true
+}
+
+// loadParsers builds the parser registry for e: user-defined parsers from
+// LOG_PARSER_CONFIG_PATH (if set) run first in descending priority order,
+// falling back to the built-in combined/common/JSON/status parsers.
+func loadParsers() (*ParserRegistry, error) {
+	reg := NewParserRegistry()
+
+	if path := os.Getenv(parserConfigPathEnvVar); path != "" {
+		cfg, err := LoadParserConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		configured, err := buildConfiguredParsers(cfg)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range configured {
+			reg.Register(p)
+		}
+	}
+
+	for _, p := range defaultParserRegistry().parsers {
+		reg.Register(p)
+	}
+
+	return reg, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegexLogParser(t *testing.T) {
+	parser := &regexLogParser{
+		name:    "test-regex",
+		pattern: combinedLogPattern,
+		fields:  map[string]int{"timestamp": 2, "method": 3, "path": 4, "status": 5, "size": 6},
+	}
+
+	entry, ok := parser.Parse(`127.0.0.1 - - [25/Dec/2019:01:17:21 +0000] "GET /api/health HTTP/1.1" 200 612`)
+	assert.True(t, ok)
+	assert.Equal(t, "GET", entry.Method)
+	assert.Equal(t, "/api/health", entry.Path)
+	assert.Equal(t, 200, entry.StatusCode)
+	assert.Equal(t, 612, entry.ResponseSize)
+
+	_, ok = parser.Parse("not a log line")
+	assert.False(t, ok)
+}
+
+func TestJSONFieldLogParser(t *testing.T) {
+	parser := &jsonFieldLogParser{
+		name: "test-json",
+		fields: map[string]string{
+			"status": "response.status_code",
+			"method": "http.request.method",
+		},
+	}
+
+	entry, ok := parser.Parse(`{"response":{"status_code":503},"http":{"request":{"method":"POST"}}}`)
+	assert.True(t, ok)
+	assert.Equal(t, 503, entry.StatusCode)
+	assert.Equal(t, "POST", entry.Method)
+
+	_, ok = parser.Parse(`{"response":{}}`)
+	assert.False(t, ok, "missing status path should not match")
+}
+
+func TestParserRegistryOrderingFirstMatchWins(t *testing.T) {
+	reg := NewParserRegistry()
+	reg.Register(&regexLogParser{
+		name:    "first",
+		pattern: statusCodePattern,
+		fields:  map[string]int{"status": 1},
+	})
+	reg.Register(&jsonStatusLogParser{})
+
+	// Both parsers match: the regex finds 404, jsonStatusLogParser finds 500.
+	entry := reg.Parse(`Error 404: {"status":500}`, "pod", "container")
+	assert.NotNil(t, entry)
+	assert.Equal(t, 404, entry.StatusCode, "first registered parser to match should win")
+	assert.Equal(t, "pod", entry.PodName)
+	assert.Equal(t, "container", entry.ContainerName)
+}
+
+func TestLoadParserConfigYAML(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "parsers.yaml")
+	contents := `
+parsers:
+  - name: envoy-json
+    type: json
+    priority: 10
+    fields:
+      status: response.status_code
+      method: http.request.method
+`
+	assert.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+
+	cfg, err := LoadParserConfig(path)
+	assert.NoError(t, err)
+	assert.Len(t, cfg.Parsers, 1)
+	assert.Equal(t, "envoy-json", cfg.Parsers[0].Name)
+	assert.Equal(t, "json", cfg.Parsers[0].Type)
+
+	parsers, err := buildConfiguredParsers(cfg)
+	assert.NoError(t, err)
+	assert.Len(t, parsers, 1)
+	assert.Equal(t, "envoy-json", parsers[0].Name())
+}
+
+func TestLoadParserConfigUnknownType(t *testing.T) {
+	cfg := &ParserConfig{Parsers: []ParserConfigEntry{{Name: "bogus", Type: "xml"}}}
+	_, err := buildConfiguredParsers(cfg)
+	assert.Error(t, err)
+}
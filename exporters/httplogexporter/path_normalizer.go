@@ -0,0 +1,308 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// routeTemplatesConfigPathEnvVar points at a YAML file listing known
+	// route templates and rewrite rules (e.g. mounted from a ConfigMap)
+	// used to normalize paths before they're ever seen as a metric label.
+	routeTemplatesConfigPathEnvVar = "ROUTE_TEMPLATES_CONFIG_PATH"
+
+	// openAPISpecPathEnvVar points at an OpenAPI/Swagger document whose
+	// `paths` keys (already in the same "/users/{id}" template form this
+	// package uses) are registered as route templates automatically, so
+	// operators don't have to hand-duplicate routes already declared in a
+	// spec.
+	openAPISpecPathEnvVar = "OPENAPI_SPEC_PATH"
+
+	// routeCardinalityCapEnvVar caps the number of distinct normalized
+	// routes tracked per (namespace, pod, container); beyond this, paths
+	// collapse to overflowRouteLabel.
+	routeCardinalityCapEnvVar = "ROUTE_CARDINALITY_CAP"
+	defaultRouteCardinalityCap = 200
+
+	// overflowRouteLabel is the route label value used once a container
+	// has exceeded its cardinality cap.
+	overflowRouteLabel = "__overflow__"
+)
+
+var (
+	numericIDPattern = regexp.MustCompile(`^\d+$`)
+	uuidPattern       = regexp.MustCompile(`(?i)^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+)
+
+// routeTemplateTrieNode is a node in the route-template trie. Literal path
+// segments are matched via children; a single paramChild handles any
+// segment when no literal child matches. paramName records the first
+// placeholder name inserted at that position; the normalized route emitted
+// on a match is the terminating node's registered template string.
+type routeTemplateTrieNode struct {
+	children   map[string]*routeTemplateTrieNode
+	paramChild *routeTemplateTrieNode
+	paramName  string
+	template   string // non-empty at nodes that terminate a registered template
+}
+
+func newRouteTemplateTrieNode() *routeTemplateTrieNode {
+	return &routeTemplateTrieNode{children: make(map[string]*routeTemplateTrieNode)}
+}
+
+// insert registers template (e.g. "/api/users/{id}/orders/{orderId}") into
+// the trie.
+func (n *routeTemplateTrieNode) insert(template string) {
+	node := n
+	for _, segment := range splitPath(template) {
+		if isTemplateParam(segment) {
+			if node.paramChild == nil {
+				node.paramChild = newRouteTemplateTrieNode()
+				node.paramChild.paramName = strings.Trim(segment, "{}")
+			}
+			node = node.paramChild
+			continue
+		}
+		child, ok := node.children[segment]
+		if !ok {
+			child = newRouteTemplateTrieNode()
+			node.children[segment] = child
+		}
+		node = child
+	}
+	node.template = template
+}
+
+// match walks path's segments against the trie, returning the registered
+// template and true on a match. Literal segments are preferred over
+// parameter segments so more specific templates win.
+func (n *routeTemplateTrieNode) match(segments []string) (string, bool) {
+	node := n
+	for _, segment := range segments {
+		switch {
+		case node.children[segment] != nil:
+			node = node.children[segment]
+		case node.paramChild != nil:
+			node = node.paramChild
+		default:
+			return "", false
+		}
+	}
+	if node.template == "" {
+		return "", false
+	}
+	return node.template, true
+}
+
+func isTemplateParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// RouteRewriteRule rewrites any path matching Pattern to the fixed Template,
+// taking precedence over trie-based template matching and the numeric/UUID
+// placeholder heuristics. Rules are tried in order; the first match wins.
+type RouteRewriteRule struct {
+	Pattern  *regexp.Regexp
+	Template string
+}
+
+// PathNormalizer rewrites raw request paths into low-cardinality route
+// labels: query strings are stripped, configured regex rewrite rules are
+// tried first, then segments matching a configured route template (which
+// may come from static config or an ingested OpenAPI spec) are rewritten to
+// that template, remaining numeric/UUID segments are replaced with
+// {id}/{uuid} placeholders, and a per-container cap collapses anything
+// beyond it to overflowRouteLabel.
+type PathNormalizer struct {
+	rewrites  []RouteRewriteRule
+	templates *routeTemplateTrieNode
+	cap       int
+
+	mu   sync.Mutex
+	seen map[string]map[string]struct{} // containerKey -> set of routes emitted so far
+}
+
+// NewPathNormalizer builds a normalizer from the given rewrite rules,
+// templates, and cardinality cap.
+func NewPathNormalizer(rewrites []RouteRewriteRule, templates []string, cap int) *PathNormalizer {
+	root := newRouteTemplateTrieNode()
+	for _, t := range templates {
+		root.insert(t)
+	}
+	return &PathNormalizer{
+		rewrites:  rewrites,
+		templates: root,
+		cap:       cap,
+		seen:      make(map[string]map[string]struct{}),
+	}
+}
+
+// routeTemplatesConfig is the YAML schema accepted via
+// routeTemplatesConfigPathEnvVar.
+type routeTemplatesConfig struct {
+	Templates []string `yaml:"templates"`
+	Rewrites  []struct {
+		Pattern  string `yaml:"pattern"`
+		Template string `yaml:"template"`
+	} `yaml:"rewrites"`
+}
+
+// openAPISpec captures just enough of an OpenAPI/Swagger document to pull
+// out its path templates; everything else (operations, schemas, ...) is
+// ignored.
+type openAPISpec struct {
+	Paths map[string]interface{} `yaml:"paths"`
+}
+
+// loadPathNormalizer builds a PathNormalizer from environment configuration,
+// defaulting to no rewrite rules or templates and defaultRouteCardinalityCap.
+func loadPathNormalizer() (*PathNormalizer, error) {
+	cap := defaultRouteCardinalityCap
+	if capStr := os.Getenv(routeCardinalityCapEnvVar); capStr != "" {
+		if parsed, err := parsePositiveInt(capStr); err == nil {
+			cap = parsed
+		}
+	}
+
+	var templates []string
+	var rewrites []RouteRewriteRule
+	if path := os.Getenv(routeTemplatesConfigPathEnvVar); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read route templates config %s: %v", path, err)
+		}
+		var cfg routeTemplatesConfig
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse route templates config %s: %v", path, err)
+		}
+		templates = cfg.Templates
+		for _, r := range cfg.Rewrites {
+			pattern, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rewrite pattern %q in %s: %v", r.Pattern, path, err)
+			}
+			rewrites = append(rewrites, RouteRewriteRule{Pattern: pattern, Template: r.Template})
+		}
+	}
+
+	if path := os.Getenv(openAPISpecPathEnvVar); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read OpenAPI spec %s: %v", path, err)
+		}
+		var spec openAPISpec
+		if err := yaml.Unmarshal(data, &spec); err != nil {
+			return nil, fmt.Errorf("failed to parse OpenAPI spec %s: %v", path, err)
+		}
+		for route := range spec.Paths {
+			templates = append(templates, route)
+		}
+	}
+
+	return NewPathNormalizer(rewrites, templates, cap), nil
+}
+
+// Normalize strips the query string from rawPath, rewrites it against
+// configured rewrite rules, templates, and ID/UUID placeholders, then
+// enforces the cardinality cap for containerKey. The second return value is
+// true when the cap was exceeded and route was collapsed to
+// overflowRouteLabel.
+func (n *PathNormalizer) Normalize(containerKey, rawPath string) (string, bool) {
+	if rawPath == "" {
+		return rawPath, false
+	}
+
+	path := rawPath
+	if idx := strings.IndexByte(path, '?'); idx != -1 {
+		path = path[:idx]
+	}
+
+	for _, rule := range n.rewrites {
+		if rule.Pattern.MatchString(path) {
+			return n.admit(containerKey, rule.Template)
+		}
+	}
+
+	segments := splitPath(path)
+	if template, ok := n.templates.match(segments); ok {
+		return n.admit(containerKey, template)
+	}
+
+	for i, segment := range segments {
+		switch {
+		case uuidPattern.MatchString(segment):
+			segments[i] = "{uuid}"
+		case numericIDPattern.MatchString(segment):
+			segments[i] = "{id}"
+		}
+	}
+	normalized := "/" + strings.Join(segments, "/")
+
+	return n.admit(containerKey, normalized)
+}
+
+// admit enforces the per-container cardinality cap, returning route as-is
+// if there's room or it was already seen, and overflowRouteLabel (with
+// capped=true) otherwise.
+func (n *PathNormalizer) admit(containerKey, route string) (string, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	routes, ok := n.seen[containerKey]
+	if !ok {
+		routes = make(map[string]struct{})
+		n.seen[containerKey] = routes
+	}
+
+	if _, ok := routes[route]; ok {
+		return route, false
+	}
+	if len(routes) >= n.cap {
+		return overflowRouteLabel, true
+	}
+	routes[route] = struct{}{}
+	return route, false
+}
+
+// pathNormalizerContainerKey builds the key PathNormalizer buckets route
+// cardinality under, shared by updateMetrics (to admit a route) and
+// stopScrapersForPod (to forget one once the container stops being scraped).
+func pathNormalizerContainerKey(namespace, podName, containerName string) string {
+	return fmt.Sprintf("%s/%s/%s", namespace, podName, containerName)
+}
+
+// Forget releases containerKey's route bookkeeping. Callers must invoke this
+// when a container is no longer scraped (see stopScrapersForPod in
+// discovery.go) - containerKey is built from the pod's name, not its UID, so
+// without this the seen map grows without bound as pods are recreated with
+// new names (deployments, autoscaling, restarts).
+func (n *PathNormalizer) Forget(containerKey string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.seen, containerKey)
+}
+
+func parsePositiveInt(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if n <= 0 {
+		return 0, fmt.Errorf("value must be positive, got %d", n)
+	}
+	return n, nil
+}
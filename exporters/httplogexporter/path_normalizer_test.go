@@ -0,0 +1,201 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathNormalizerIDAndUUIDPlaceholders(t *testing.T) {
+	n := NewPathNormalizer(nil, nil, 100)
+
+	got, capped := n.Normalize("c1", "/api/users/12345")
+	assert.Equal(t, "/api/users/{id}", got)
+	assert.False(t, capped)
+
+	got, capped = n.Normalize("c1", "/api/users/3fa85f64-5717-4562-b3fc-2c963f66afa6")
+	assert.Equal(t, "/api/users/{uuid}", got)
+	assert.False(t, capped)
+
+	got, capped = n.Normalize("c1", "/api/health?verbose=true")
+	assert.Equal(t, "/api/health", got)
+	assert.False(t, capped)
+}
+
+func TestPathNormalizerConfiguredTemplate(t *testing.T) {
+	n := NewPathNormalizer(nil, []string{"/api/users/{id}/orders/{orderId}"}, 100)
+
+	got, capped := n.Normalize("c1", "/api/users/42/orders/abc-def")
+	assert.Equal(t, "/api/users/{id}/orders/{orderId}", got)
+	assert.False(t, capped)
+}
+
+func TestPathNormalizerRewriteRuleTakesPrecedence(t *testing.T) {
+	rewrites := []RouteRewriteRule{
+		{Pattern: regexp.MustCompile(`^/users/\d+/orders/[^/]+$`), Template: "/users/:id/orders/:oid"},
+	}
+	n := NewPathNormalizer(rewrites, []string{"/users/{id}/orders/{orderId}"}, 100)
+
+	got, capped := n.Normalize("c1", "/users/42/orders/abc-def")
+	assert.Equal(t, "/users/:id/orders/:oid", got)
+	assert.False(t, capped)
+}
+
+func TestPathNormalizerCardinalityCap(t *testing.T) {
+	n := NewPathNormalizer(nil, nil, 2)
+
+	got, capped := n.Normalize("c1", "/a")
+	assert.Equal(t, "/a", got)
+	assert.False(t, capped)
+
+	got, capped = n.Normalize("c1", "/b")
+	assert.Equal(t, "/b", got)
+	assert.False(t, capped)
+
+	got, capped = n.Normalize("c1", "/c")
+	assert.Equal(t, overflowRouteLabel, got)
+	assert.True(t, capped)
+
+	// A different container key gets its own budget.
+	got, capped = n.Normalize("c2", "/a")
+	assert.Equal(t, "/a", got)
+	assert.False(t, capped)
+}
+
+func TestPathNormalizerForget(t *testing.T) {
+	n := NewPathNormalizer(nil, nil, 1)
+
+	got, capped := n.Normalize("c1", "/a")
+	assert.Equal(t, "/a", got)
+	assert.False(t, capped)
+
+	got, capped = n.Normalize("c1", "/b")
+	assert.Equal(t, overflowRouteLabel, got)
+	assert.True(t, capped)
+
+	n.Forget("c1")
+
+	got, capped = n.Normalize("c1", "/b")
+	assert.Equal(t, "/b", got)
+	assert.False(t, capped)
+}
+
+func TestLoadPathNormalizerRouteTemplatesConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "routes.yaml")
+	contents := `
+templates:
+  - /api/users/{id}/orders/{orderId}
+rewrites:
+  - pattern: '^/internal/\d+$'
+    template: /internal/:id
+`
+	assert.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	t.Setenv(routeTemplatesConfigPathEnvVar, path)
+	t.Setenv(openAPISpecPathEnvVar, "")
+	t.Setenv(routeCardinalityCapEnvVar, "")
+
+	n, err := loadPathNormalizer()
+	assert.NoError(t, err)
+
+	got, capped := n.Normalize("c1", "/api/users/42/orders/abc-def")
+	assert.Equal(t, "/api/users/{id}/orders/{orderId}", got)
+	assert.False(t, capped)
+
+	got, capped = n.Normalize("c2", "/internal/123")
+	assert.Equal(t, "/internal/:id", got, "rewrite rule should take precedence over the numeric {id} placeholder")
+	assert.False(t, capped)
+}
+
+func TestLoadPathNormalizerRouteTemplatesConfigInvalidRewrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "routes.yaml")
+	contents := `
+rewrites:
+  - pattern: '['
+    template: /broken
+`
+	assert.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	t.Setenv(routeTemplatesConfigPathEnvVar, path)
+	t.Setenv(openAPISpecPathEnvVar, "")
+
+	_, err := loadPathNormalizer()
+	assert.Error(t, err)
+}
+
+func TestLoadPathNormalizerOpenAPISpec(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "openapi.yaml")
+	contents := `
+paths:
+  /api/users/{id}:
+    get:
+      summary: get a user
+  /api/health:
+    get:
+      summary: health check
+`
+	assert.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	t.Setenv(routeTemplatesConfigPathEnvVar, "")
+	t.Setenv(openAPISpecPathEnvVar, path)
+
+	n, err := loadPathNormalizer()
+	assert.NoError(t, err)
+
+	got, capped := n.Normalize("c1", "/api/users/42")
+	assert.Equal(t, "/api/users/{id}", got)
+	assert.False(t, capped)
+
+	got, capped = n.Normalize("c1", "/api/health")
+	assert.Equal(t, "/api/health", got)
+	assert.False(t, capped)
+}
+
+func TestLoadPathNormalizerMissingConfigFile(t *testing.T) {
+	t.Setenv(routeTemplatesConfigPathEnvVar, filepath.Join(t.TempDir(), "does-not-exist.yaml"))
+	t.Setenv(openAPISpecPathEnvVar, "")
+
+	_, err := loadPathNormalizer()
+	assert.Error(t, err)
+}
+
+func TestLoadPathNormalizerDefaults(t *testing.T) {
+	t.Setenv(routeTemplatesConfigPathEnvVar, "")
+	t.Setenv(openAPISpecPathEnvVar, "")
+	t.Setenv(routeCardinalityCapEnvVar, "")
+
+	n, err := loadPathNormalizer()
+	assert.NoError(t, err)
+	assert.Equal(t, defaultRouteCardinalityCap, n.cap)
+}
+
+func TestParsePositiveInt(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       string
+		expected  int
+		expectErr bool
+	}{
+		{name: "valid positive integer", raw: "200", expected: 200},
+		{name: "trailing garbage is rejected", raw: "200abc", expectErr: true},
+		{name: "leading garbage is rejected", raw: "abc200", expectErr: true},
+		{name: "zero is rejected", raw: "0", expectErr: true},
+		{name: "negative is rejected", raw: "-5", expectErr: true},
+		{name: "empty is rejected", raw: "", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePositiveInt(tt.raw)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
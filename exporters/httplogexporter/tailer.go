@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// tailerQueueSizeEnvVar configures how many read-but-not-yet-processed
+	// log lines a tailer buffers between its reader and processor
+	// goroutines before it starts dropping lines.
+	tailerQueueSizeEnvVar  = "LOG_TAILER_QUEUE_SIZE"
+	defaultTailerQueueSize = 1000
+)
+
+// ContainerTailer streams logs from a single pod container with
+// Follow: true, replacing the old approach of periodically re-fetching the
+// last N lines with TailLines (which double-counted or dropped entries
+// around restarts). It checkpoints the timestamp of the last line it
+// processed so that on reconnect - after a stream error, pod restart, or
+// exporter restart - it resumes with SinceTime instead of re-reading
+// history.
+type ContainerTailer struct {
+	exporter *HTTPLogExporter
+
+	// namespace is the pod's actual namespace, not necessarily e's
+	// namespace/namespaceSelector: a single exporter can watch many
+	// namespaces (see namespaces.go), so this - not e.namespace - is what
+	// labels every metric and log line this tailer produces.
+	namespace     string
+	podUID        string
+	podName       string
+	containerName string
+	restartCount  int32
+
+	// logFormat, if non-empty, names the parser builtinParserByFormat
+	// should try first (see the pod's logFormatAnnotation), before
+	// autodetection; lines it doesn't match still fall through.
+	logFormat string
+
+	// openLogStream opens t's Follow:true log stream. Left nil in
+	// production, where tailOnce falls back to t.exporter.clientset; tests
+	// set it to stream canned content without a real API server.
+	openLogStream func(ctx context.Context, opts *corev1.PodLogOptions) (io.ReadCloser, error)
+}
+
+// Run tails the container's logs until ctx is canceled, reconnecting with
+// exponential backoff (capped at e.exporter.scrapeInterval) whenever the
+// stream ends or errors.
+func (t *ContainerTailer) Run(ctx context.Context) {
+	backoffCap := t.exporter.scrapeInterval
+	if backoffCap <= 0 {
+		backoffCap = defaultScrapeInterval
+	}
+	backoff := time.Second
+
+	for ctx.Err() == nil {
+		start := time.Now()
+		linesProcessed, err := t.tailOnce(ctx)
+		duration := time.Since(start)
+		if err != nil && ctx.Err() == nil {
+			t.exporter.log().Warn("tailer disconnected, reconnecting",
+				"namespace", t.namespace,
+				"pod", t.podName,
+				"container", t.containerName,
+				"lines_processed", linesProcessed,
+				"duration", duration,
+				"backoff", backoff,
+				"error", err,
+			)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > backoffCap {
+				backoff = backoffCap
+			}
+			continue
+		}
+		backoff = time.Second
+	}
+}
+
+// checkpointKey identifies this tailer's entry in the checkpoint store.
+func (t *ContainerTailer) checkpointKey() string {
+	return checkpointKey(t.podUID, t.containerName, t.restartCount)
+}
+
+// tailOnce opens a single Follow:true log stream, resuming from the last
+// checkpoint (if any), and processes lines until the stream ends or ctx is
+// canceled. It returns the number of lines read off the stream (whether or
+// not each one was successfully parsed) so Run can report it on reconnect.
+// The returned error is never nil: a stream that simply ends yields
+// "log stream ended", so Run reconnects, since the container may still be
+// producing logs.
+func (t *ContainerTailer) tailOnce(ctx context.Context) (int, error) {
+	opts := &corev1.PodLogOptions{
+		Container:  t.containerName,
+		Follow:     true,
+		Timestamps: true,
+	}
+	var since time.Time
+	if checkpoint, ok := t.exporter.checkpoints.Get(t.checkpointKey()); ok {
+		since = checkpoint
+		sinceTime := metav1.NewTime(since)
+		opts.SinceTime = &sinceTime
+	}
+
+	streamLogs := t.openLogStream
+	if streamLogs == nil {
+		streamLogs = func(ctx context.Context, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+			return t.exporter.clientset.CoreV1().Pods(t.namespace).GetLogs(t.podName, opts).Stream(ctx)
+		}
+	}
+	stream, err := streamLogs(ctx, opts)
+	if err != nil {
+		t.exporter.scrapeErrors.WithLabelValues(t.namespace, t.podName, t.containerName, "stream_open").Inc()
+		return 0, fmt.Errorf("failed to open log stream: %v", err)
+	}
+	defer stream.Close()
+
+	queueSize := tailerQueueSize()
+	lines := make(chan string, queueSize)
+	processingDone := make(chan struct{})
+
+	go func() {
+		defer close(processingDone)
+		for line := range lines {
+			t.processLine(line, since)
+		}
+	}()
+
+	linesRead := 0
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		linesRead++
+		select {
+		case lines <- scanner.Text():
+		default:
+			t.exporter.droppedLinesTotal.WithLabelValues(t.namespace, t.podName, t.containerName).Inc()
+		}
+	}
+	close(lines)
+	<-processingDone
+
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		t.exporter.scrapeErrors.WithLabelValues(t.namespace, t.podName, t.containerName, "stream_read").Inc()
+		return linesRead, fmt.Errorf("error reading log stream: %v", err)
+	}
+	return linesRead, fmt.Errorf("log stream ended")
+}
+
+// processLine strips the Kubernetes-added timestamp prefix (present
+// because Timestamps: true is set), skips lines already accounted for by
+// since, parses the remainder as an HTTP log line, updates metrics, and
+// advances the checkpoint.
+//
+// since is the checkpoint this tailOnce call resumed from (the zero Time if
+// there wasn't one). opts.SinceTime only carries whole-second precision on
+// the wire (see metav1.Time.MarshalQueryParameter), so kubelet may replay
+// every line from that same second on reconnect, including ones already
+// processed - skipping anything not strictly after since, using each line's
+// own full-precision timestamp, is what actually prevents double-counting.
+func (t *ContainerTailer) processLine(raw string, since time.Time) {
+	timestamp, line, hasTimestamp := splitTimestampPrefix(raw)
+	if hasTimestamp && !timestamp.After(since) {
+		return
+	}
+
+	if entry := t.exporter.parseLogLineWithFormat(line, t.podName, t.containerName, t.logFormat); entry != nil {
+		entry.Namespace = t.namespace
+		t.exporter.updateMetrics(entry)
+	}
+
+	if hasTimestamp {
+		if err := t.exporter.checkpoints.Set(t.checkpointKey(), timestamp); err != nil {
+			t.exporter.log().Error("failed to persist checkpoint",
+				"namespace", t.namespace,
+				"pod", t.podName,
+				"container", t.containerName,
+				"error", err,
+			)
+		}
+	}
+
+	t.exporter.lastScrapeTime.WithLabelValues(t.namespace, t.podName, t.containerName).SetToCurrentTime()
+}
+
+// splitTimestampPrefix separates the RFC3339Nano timestamp Kubernetes
+// prepends to each line (via PodLogOptions.Timestamps) from the rest of
+// the line.
+func splitTimestampPrefix(raw string) (time.Time, string, bool) {
+	idx := strings.IndexByte(raw, ' ')
+	if idx == -1 {
+		return time.Time{}, raw, false
+	}
+	ts, err := time.Parse(time.RFC3339Nano, raw[:idx])
+	if err != nil {
+		return time.Time{}, raw, false
+	}
+	return ts, raw[idx+1:], true
+}
+
+func tailerQueueSize() int {
+	if raw := os.Getenv(tailerQueueSizeEnvVar); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultTailerQueueSize
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSplitTimestampPrefix(t *testing.T) {
+	tests := []struct {
+		name         string
+		raw          string
+		expectedLine string
+		expectedOK   bool
+	}{
+		{
+			name:         "valid RFC3339Nano prefix",
+			raw:          `2024-01-02T03:04:05.123456789Z 127.0.0.1 - - [02/Jan/2024:03:04:05 +0000] "GET /api/health HTTP/1.1" 200 12`,
+			expectedLine: `127.0.0.1 - - [02/Jan/2024:03:04:05 +0000] "GET /api/health HTTP/1.1" 200 12`,
+			expectedOK:   true,
+		},
+		{
+			name:         "no space in line",
+			raw:          "nospacehere",
+			expectedLine: "nospacehere",
+			expectedOK:   false,
+		},
+		{
+			name:         "unparsable prefix",
+			raw:          "not-a-timestamp rest of line",
+			expectedLine: "not-a-timestamp rest of line",
+			expectedOK:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, line, ok := splitTimestampPrefix(tt.raw)
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.Equal(t, tt.expectedLine, line)
+			if ok {
+				assert.Equal(t, 2024, ts.Year())
+			}
+		})
+	}
+}
+
+func TestTailerQueueSize(t *testing.T) {
+	t.Setenv(tailerQueueSizeEnvVar, "")
+	assert.Equal(t, defaultTailerQueueSize, tailerQueueSize())
+
+	t.Setenv(tailerQueueSizeEnvVar, "50")
+	assert.Equal(t, 50, tailerQueueSize())
+
+	t.Setenv(tailerQueueSizeEnvVar, "invalid")
+	assert.Equal(t, defaultTailerQueueSize, tailerQueueSize())
+
+	t.Setenv(tailerQueueSizeEnvVar, "-5")
+	assert.Equal(t, defaultTailerQueueSize, tailerQueueSize())
+}
+
+func TestContainerTailerCheckpointKey(t *testing.T) {
+	exporter := &HTTPLogExporter{namespace: "ns"}
+	tailer := &ContainerTailer{exporter: exporter, podUID: "pod-uid-123", podName: "pod", containerName: "app", restartCount: 1}
+
+	assert.Equal(t, "pod-uid-123/app/1", tailer.checkpointKey())
+}
+
+// requestCount reads the current value of exporter's http_requests_total
+// counter for the given route.
+func requestCount(t *testing.T, exporter *HTTPLogExporter, namespace, pod, container, statusCode, route string) float64 {
+	t.Helper()
+	metric := &dto.Metric{}
+	exporter.httpRequestsTotal.WithLabelValues(namespace, pod, container, statusCode, route).Write(metric)
+	return metric.GetCounter().GetValue()
+}
+
+// TestTailOnceSkipsReplayedLinesWithinCheckpointedSecond guards against the
+// double-counting bug fixed in tailOnce: SinceTime only carries whole-second
+// precision over the wire (metav1.Time.MarshalQueryParameter uses
+// time.RFC3339), so on every reconnect kubelet can replay every line from
+// the same second as the last checkpoint, including ones already processed.
+func TestTailOnceSkipsReplayedLinesWithinCheckpointedSecond(t *testing.T) {
+	exporter := newTestExporter()
+	tailer := &ContainerTailer{
+		exporter:      exporter,
+		namespace:     "ns",
+		podUID:        "pod-uid-1",
+		podName:       "pod",
+		containerName: "app",
+	}
+
+	lineA := `2024-01-02T03:04:05.100000000Z 127.0.0.1 - - [02/Jan/2024:03:04:05 +0000] "GET /api/a HTTP/1.1" 200 10`
+	lineB := `2024-01-02T03:04:05.900000000Z 127.0.0.1 - - [02/Jan/2024:03:04:05 +0000] "GET /api/b HTTP/1.1" 200 10`
+	firstStream := lineA + "\n" + lineB + "\n"
+
+	tailer.openLogStream = func(ctx context.Context, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(firstStream)), nil
+	}
+	_, err := tailer.tailOnce(context.Background())
+	assert.EqualError(t, err, "log stream ended")
+
+	assert.Equal(t, float64(1), requestCount(t, exporter, "ns", "pod", "app", "200", "/api/a"))
+	assert.Equal(t, float64(1), requestCount(t, exporter, "ns", "pod", "app", "200", "/api/b"))
+
+	// Simulate a reconnect: kubelet replays every line from the checkpointed
+	// second (both already-processed lines), plus one genuinely new line.
+	lineC := `2024-01-02T03:04:06.000000000Z 127.0.0.1 - - [02/Jan/2024:03:04:06 +0000] "GET /api/c HTTP/1.1" 200 10`
+	secondStream := lineA + "\n" + lineB + "\n" + lineC + "\n"
+
+	tailer.openLogStream = func(ctx context.Context, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(secondStream)), nil
+	}
+	_, err = tailer.tailOnce(context.Background())
+	assert.EqualError(t, err, "log stream ended")
+
+	assert.Equal(t, float64(1), requestCount(t, exporter, "ns", "pod", "app", "200", "/api/a"), "replayed line must not be double-counted")
+	assert.Equal(t, float64(1), requestCount(t, exporter, "ns", "pod", "app", "200", "/api/b"), "replayed line must not be double-counted")
+	assert.Equal(t, float64(1), requestCount(t, exporter, "ns", "pod", "app", "200", "/api/c"), "genuinely new line must still be counted")
+}
+
+// TestTailOnceRecordsStreamOpenError verifies that a failed log stream open
+// increments scrapeErrors instead of going unreported - http_log_scraper_errors_total
+// previously had no call site left after the list-based scrape loop was
+// replaced by discovery/tailers, so it always read zero regardless of real
+// failures.
+func TestTailOnceRecordsStreamOpenError(t *testing.T) {
+	exporter := newTestExporter()
+	tailer := &ContainerTailer{
+		exporter:      exporter,
+		namespace:     "ns",
+		podUID:        "pod-uid-1",
+		podName:       "pod",
+		containerName: "app",
+		openLogStream: func(ctx context.Context, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+			return nil, assert.AnError
+		},
+	}
+
+	_, err := tailer.tailOnce(context.Background())
+	assert.Error(t, err)
+
+	metric := &dto.Metric{}
+	exporter.scrapeErrors.WithLabelValues("ns", "pod", "app", "stream_open").Write(metric)
+	assert.Equal(t, float64(1), metric.GetCounter().GetValue())
+}